@@ -0,0 +1,99 @@
+package jsonlogic
+
+import "context"
+
+// Option limits the resources a single evaluation may use, so rules from untrusted sources can't run unbounded.
+// An evaluation which goes over a limit returns a *BudgetError, which matches ErrBudgetExceeded.
+type Option func(*limits)
+
+// limits are the resources an evaluation may use, where 0 is unlimited.
+type limits struct {
+	maxDepth      int
+	maxOperations int
+	maxArraySize  int
+}
+
+// WithMaxDepth limits how deeply operators may be nested, counting those evaluated by 'map' and the like for each element.
+// ApplyContext rejects a rule with objects nested deeper than the limit before evaluating it.
+func WithMaxDepth(depth int) Option {
+	return func(l *limits) {
+		l.maxDepth = depth
+	}
+}
+
+// WithMaxOperations limits the total number of operators evaluated, so each element 'map' visits counts towards it.
+func WithMaxOperations(operations int) Option {
+	return func(l *limits) {
+		l.maxOperations = operations
+	}
+}
+
+// WithMaxArraySize limits the number of elements in any array produced by an operator, such as 'map' or 'merge'.
+// Arrays read from the data aren't limited, including when 'var' or an operator such as 'if' passes one on.
+func WithMaxArraySize(size int) Option {
+	return func(l *limits) {
+		l.maxArraySize = size
+	}
+}
+
+// limited reports whether any limit has been set.
+func (l limits) limited() bool {
+	return l.maxDepth > 0 || l.maxOperations > 0 || l.maxArraySize > 0
+}
+
+// newLimits returns the limits set by options.
+func newLimits(options []Option) limits {
+	var l limits
+	for _, option := range options {
+		option(&l)
+	}
+	return l
+}
+
+// newEvaluation starts an evaluation using the operators of engine which stops when ctx is done or a limit is exceeded.
+func newEvaluation(ctx context.Context, engine *Engine, options []Option) *evaluation {
+	return &evaluation{engine: engine, ctx: ctx, done: ctx.Done(), limits: newLimits(options)}
+}
+
+// enter is called before the operator at a location is evaluated, returning an error if the evaluation should stop.
+// Each successful call must be followed by a call to leave once the operator has been evaluated.
+func (ev *evaluation) enter(at *location) error {
+	select {
+	case <-ev.done:
+		return ev.ctx.Err()
+	default:
+	}
+
+	if ev.limits.maxDepth > 0 && ev.depth >= ev.limits.maxDepth {
+		return &BudgetError{Limit: "depth", Max: ev.limits.maxDepth, Path: at.String()}
+	}
+	if ev.limits.maxOperations > 0 && ev.operations >= ev.limits.maxOperations {
+		return &BudgetError{Limit: "operations", Max: ev.limits.maxOperations, Path: at.String()}
+	}
+
+	ev.depth++
+	ev.operations++
+	return nil
+}
+
+// leave is called once an operator has been evaluated.
+func (ev *evaluation) leave() {
+	ev.depth--
+}
+
+// passThrough are the operators whose result is one of their arguments or read from the data rather than produced
+// by them, so an array they return has either been checked already or isn't limited.
+var passThrough = map[string]bool{
+	"var": true, "if": true, "?:": true, "and": true, "or": true, "log": true,
+}
+
+// checkSize returns an error if value, produced at a location, is an array larger than allowed.
+func (ev *evaluation) checkSize(value interface{}, at *location) error {
+	if ev.limits.maxArraySize <= 0 {
+		return nil
+	}
+	if array, length := isArray(value); array && length > ev.limits.maxArraySize {
+		return &BudgetError{Limit: "array size", Max: ev.limits.maxArraySize, Path: at.String()}
+	}
+	return nil
+}
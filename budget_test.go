@@ -0,0 +1,170 @@
+package jsonlogic
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := ApplyContext(ctx, `{"==":[1, 1]}`, `{}`)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("rule should return the context error, instead returned %v, %v", result, err)
+	}
+}
+
+func TestApplyContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	_, err := ApplyContext(ctx, `{"var":"a"}`, `{"a":1}`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("rule should return the context error, instead returned %v", err)
+	}
+}
+
+func TestApplyContextCanceledDuringEvaluation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	engine := NewEngine()
+	engine.AddOperation("cancel", func(ctx *Context, args []interface{}) (interface{}, error) {
+		calls++
+		cancel()
+		return nil, nil
+	})
+
+	_, err := engine.ApplyContext(ctx, `{"map":[[1, 2, 3], {"cancel":[]}]}`, `{}`)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("rule should return the context error, instead returned %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("evaluation should stop once canceled, instead called the operator %d times", calls)
+	}
+}
+
+func TestOperationContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "tenant")
+
+	engine := NewEngine()
+	engine.AddOperation("tenant", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return ctx.Context().Value(key{}), nil
+	})
+
+	result, err := engine.ApplyContext(ctx, `{"tenant":[]}`, `{}`)
+	if err != nil || result != "tenant" {
+		t.Fatalf("rule should return tenant, instead returned %v, %v", result, err)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	rule := strings.Repeat(`{"!":`, 5) + `true` + strings.Repeat(`}`, 5)
+
+	if _, err := ApplyContext(context.Background(), rule, `{}`, WithMaxDepth(5)); err != nil {
+		t.Fatalf("rule should be within the limit, instead returned %v", err)
+	}
+
+	_, err := ApplyContext(context.Background(), rule, `{}`, WithMaxDepth(4))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("rule should exceed the budget, instead returned %v", err)
+	}
+
+	budget, ok := err.(*BudgetError)
+	if !ok || budget.Limit != "depth" || budget.Path != "![0].![0].![0].![0].!" {
+		t.Fatalf("error should be for the depth at ![0].![0].![0].![0].!, instead returned %#v", err)
+	}
+}
+
+func TestMaxDepthDeepRule(t *testing.T) {
+	depth := 9000
+	rule := strings.Repeat(`{"!":`, depth) + `true` + strings.Repeat(`}`, depth)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := ApplyContext(ctx, rule, `{}`, WithMaxDepth(10))
+	runtime.ReadMemStats(&after)
+
+	budget, ok := err.(*BudgetError)
+	if !ok || budget.Limit != "depth" || budget.Path != strings.Repeat("![0].", 10)+"!" {
+		t.Fatalf("rule should exceed the depth while it is parsed, instead returned %v", err)
+	}
+
+	// Parsing stops at the limit rather than compiling and optimizing the whole rule first
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Fatalf("rule should fail without compiling all of it, instead allocated %d bytes", allocated)
+	}
+}
+
+func TestApplyContextDeadlineDeepRule(t *testing.T) {
+	depth := 9000
+	rule := strings.Repeat(`{"!":`, depth) + `true` + strings.Repeat(`}`, depth)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := ApplyContext(ctx, rule, `{}`)
+	runtime.ReadMemStats(&after)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("rule should return the context error, instead returned %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Fatalf("rule should fail without compiling all of it, instead allocated %d bytes", allocated)
+	}
+}
+
+func TestMaxOperations(t *testing.T) {
+	rule := `{"map":[{"var":"items"}, {"*":[{"var":""}, 2]}]}`
+	data := `{"items":[1,2,3,4,5,6,7,8,9,10]}`
+
+	// map, var and then a '*' and var for each element
+	if _, err := ApplyContext(context.Background(), rule, data, WithMaxOperations(22)); err != nil {
+		t.Fatalf("rule should be within the limit, instead returned %v", err)
+	}
+
+	_, err := ApplyContext(context.Background(), rule, data, WithMaxOperations(21))
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("rule should exceed the budget, instead returned %v", err)
+	}
+}
+
+func TestMaxArraySize(t *testing.T) {
+	rules := []string{
+		`{"merge":[[1, 2], [3, 4]]}`,
+		`{"map":[{"var":"items"}, {"var":""}]}`,
+		`[1, 2, 3, 4]`,
+	}
+
+	for _, rule := range rules {
+		_, err := ApplyContext(context.Background(), rule, `{"items":[1,2,3,4]}`, WithMaxArraySize(3))
+
+		budget, ok := err.(*BudgetError)
+		if !ok || budget.Limit != "array size" {
+			t.Fatalf("rule %s should exceed the array size, instead returned %v", rule, err)
+		}
+	}
+
+	if _, err := ApplyContext(context.Background(), rules[0], `{}`, WithMaxArraySize(4)); err != nil {
+		t.Fatalf("rule should be within the limit, instead returned %v", err)
+	}
+	// Arrays read from the data are only limited once an operator produces a new one from them
+	for _, rule := range []string{`{"var":"items"}`, `{"if":[true, {"var":"items"}]}`, `{"or":[{"var":"items"}]}`} {
+		if _, err := ApplyContext(context.Background(), rule, `{"items":[1,2,3,4]}`, WithMaxArraySize(3)); err != nil {
+			t.Fatalf("rule %s should pass on the data, instead returned %v", rule, err)
+		}
+	}
+}
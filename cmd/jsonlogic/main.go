@@ -0,0 +1,189 @@
+// Command jsonlogic evaluates a JsonLogic rule against json data and prints the result as json.
+//
+// Usage:
+//
+//	jsonlogic [flags] [rule]
+//	jsonlogic repl [-data file] [-history file]
+//
+// The rule is given inline as the first argument, read from a file with -rule, or read from stdin when neither
+// is given. Data is read from a file with -data, which may be - for stdin, and defaults to an empty object.
+//
+// With -ndjson each line of the data is a separate record, the rule being evaluated against each in turn and
+// each result printed on its own line.
+//
+// With -exit-status the exit status is 0 when every result is truthy and 1 when any is falsy, so rules can be
+// used as checks in shell pipelines. Otherwise it is 0 on success. Errors always exit with status 2.
+//
+// The repl subcommand starts an interactive session for writing rules, where each rule entered is evaluated
+// against the data and its result shown. Enter :help within the session for its commands.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	jsonlogic "github.com/GeorgeD19/json-logic-go"
+)
+
+// Exit statuses
+const (
+	exitOK    = 0
+	exitFalsy = 1
+	exitError = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with args, returning the exit status.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "repl" {
+		return runREPL(args[1:], stdin, stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("jsonlogic", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: jsonlogic [flags] [rule]")
+		fmt.Fprintln(stderr, "       jsonlogic repl [-data file] [-history file]")
+		flags.PrintDefaults()
+	}
+	ruleFile := flags.String("rule", "", "read the rule from `file`, - for stdin")
+	dataFile := flags.String("data", "", "read the data from `file`, - for stdin")
+	pretty := flags.Bool("pretty", false, "indent the result")
+	ndjson := flags.Bool("ndjson", false, "evaluate the rule against each line of the data")
+	exitStatus := flags.Bool("exit-status", false, "exit with 1 if any result is falsy")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	fail := func(err error) int {
+		fmt.Fprintf(stderr, "jsonlogic: %s\n", err)
+		return exitError
+	}
+
+	if flags.NArg() > 1 || (flags.NArg() == 1 && *ruleFile != "") {
+		flags.Usage()
+		return exitError
+	}
+	if *pretty && *ndjson {
+		return fail(errors.New("-pretty can't be used with -ndjson"))
+	}
+
+	// The rule is read from stdin when it isn't given any other way
+	rule := flags.Arg(0)
+	if flags.NArg() == 0 && *ruleFile == "" {
+		*ruleFile = "-"
+	}
+	if *ruleFile == "-" && *dataFile == "-" {
+		return fail(errors.New("the rule and data can't both be read from stdin"))
+	}
+	if *ruleFile != "" {
+		raw, err := readFile(*ruleFile, stdin)
+		if err != nil {
+			return fail(err)
+		}
+		rule = string(raw)
+	}
+
+	program, err := jsonlogic.Compile(rule)
+	if err != nil {
+		return fail(err)
+	}
+
+	var data io.Reader = strings.NewReader(``)
+	if *dataFile == "-" {
+		data = stdin
+	} else if *dataFile != "" {
+		file, err := os.Open(*dataFile)
+		if err != nil {
+			return fail(err)
+		}
+		defer file.Close()
+		data = file
+	}
+
+	if *ndjson {
+		return evaluateLines(program, data, stdout, stderr, *exitStatus)
+	}
+
+	raw, err := io.ReadAll(data)
+	if err != nil {
+		return fail(err)
+	}
+	result, err := program.Evaluate(string(raw))
+	if err != nil {
+		return fail(err)
+	}
+	if err := writeResult(stdout, result, *pretty); err != nil {
+		return fail(err)
+	}
+	return status(result, *exitStatus)
+}
+
+// evaluateLines evaluates program against each line of data, skipping blank lines. A line which fails is reported
+// and the rest are still evaluated, though the exit status is then exitError.
+func evaluateLines(program *jsonlogic.Program, data io.Reader, stdout io.Writer, stderr io.Writer, exitStatus bool) int {
+	code := exitOK
+	scanner := bufio.NewScanner(data)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		record := strings.TrimSpace(scanner.Text())
+		if record == "" {
+			continue
+		}
+
+		result, err := program.Evaluate(record)
+		if err == nil {
+			err = writeResult(stdout, result, false)
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "jsonlogic: line %d: %s\n", line, err)
+			code = exitError
+			continue
+		}
+
+		if code == exitOK {
+			code = status(result, exitStatus)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "jsonlogic: %s\n", err)
+		return exitError
+	}
+	return code
+}
+
+// status is the exit status for a result, which is only exitFalsy for falsy results when asked for.
+func status(result interface{}, exitStatus bool) int {
+	if exitStatus && !jsonlogic.AsBool(result) {
+		return exitFalsy
+	}
+	return exitOK
+}
+
+// writeResult writes result as json on its own line.
+func writeResult(w io.Writer, result interface{}, pretty bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(result)
+}
+
+// readFile reads the whole of a file, or stdin when the name is -.
+func readFile(name string, stdin io.Reader) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(name)
+}
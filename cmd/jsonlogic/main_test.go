@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeTemp(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestInlineRule(t *testing.T) {
+	code, stdout, _ := runCommand(t, `{"temp":100}`, "-data", "-", `{"<":[{"var":"temp"}, 110]}`)
+
+	if code != exitOK || stdout != "true\n" {
+		t.Fatalf("command should print true, instead exited %d with %q", code, stdout)
+	}
+}
+
+func TestRuleFromStdin(t *testing.T) {
+	data := writeTemp(t, "data.json", `{"a":"x","b":"y"}`)
+	code, stdout, _ := runCommand(t, `{"cat":[{"var":"a"}, {"var":"b"}]}`, "-data", data)
+
+	if code != exitOK || stdout != "\"xy\"\n" {
+		t.Fatalf("command should print \"xy\", instead exited %d with %q", code, stdout)
+	}
+}
+
+func TestRuleFromFile(t *testing.T) {
+	rule := writeTemp(t, "rule.json", `{"merge":[[1], [2]]}`)
+	code, stdout, _ := runCommand(t, ``, "-rule", rule, "-pretty")
+
+	if code != exitOK || stdout != "[\n  1,\n  2\n]\n" {
+		t.Fatalf("command should print an indented array, instead exited %d with %q", code, stdout)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	rule := `{">":[{"var":"a"}, 3]}`
+
+	if code, _, _ := runCommand(t, `{"a":5}`, "-exit-status", "-data", "-", rule); code != exitOK {
+		t.Fatalf("truthy result should exit %d, instead exited %d", exitOK, code)
+	}
+	if code, _, _ := runCommand(t, `{"a":1}`, "-exit-status", "-data", "-", rule); code != exitFalsy {
+		t.Fatalf("falsy result should exit %d, instead exited %d", exitFalsy, code)
+	}
+	if code, _, _ := runCommand(t, `{"a":1}`, "-data", "-", rule); code != exitOK {
+		t.Fatalf("falsy result without -exit-status should exit %d, instead exited %d", exitOK, code)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	data := "{\"a\":5}\n\n{\"a\":1}\n{\"a\":9}\n"
+	code, stdout, _ := runCommand(t, data, "-ndjson", "-exit-status", "-data", "-", `{">":[{"var":"a"}, 3]}`)
+
+	if stdout != "true\nfalse\ntrue\n" {
+		t.Fatalf("command should print a result per line, instead printed %q", stdout)
+	}
+	if code != exitFalsy {
+		t.Fatalf("a falsy record should exit %d, instead exited %d", exitFalsy, code)
+	}
+}
+
+func TestNDJSONInvalidLine(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "{\"a\":1}\n{\"a\":\n{\"a\":2}\n", "-ndjson", "-data", "-", `{"var":"a"}`)
+
+	if stdout != "1\n2\n" || !strings.Contains(stderr, "line 2") {
+		t.Fatalf("command should skip the invalid line, instead printed %q and %q", stdout, stderr)
+	}
+	if code != exitError {
+		t.Fatalf("an invalid record should exit %d, instead exited %d", exitError, code)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	commands := [][]string{
+		{`{"nope":[]}`},
+		{`{"==":[1,`},
+		{"-rule", "-", "-data", "-"},
+		{"-pretty", "-ndjson", `true`},
+		{"-rule", "missing.json"},
+		{`true`, `false`},
+	}
+
+	for _, args := range commands {
+		if code, _, _ := runCommand(t, ``, args...); code != exitError {
+			t.Fatalf("command %v should exit %d, instead exited %d", args, exitError, code)
+		}
+	}
+}
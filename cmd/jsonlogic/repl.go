@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jsonlogic "github.com/GeorgeD19/json-logic-go"
+)
+
+const replHelp = `Enter a rule on a single line to evaluate it against the data, or one of:
+  :load file    load the data from a file
+  :data [json]  show the data, or replace it
+  :trace        toggle showing how each rule is evaluated
+  :operators    list the built in and registered operators
+  :history      list the rules entered so far
+  :help         show this help
+  :quit         leave`
+
+// repl is an interactive session where rules are entered line by line and evaluated against the same data.
+type repl struct {
+	out     io.Writer
+	data    string
+	trace   bool
+	history []string
+
+	// historyFile is appended with each rule so history is kept between sessions
+	historyFile string
+}
+
+// runREPL runs the repl subcommand, reading commands from stdin until it is closed or :quit is entered.
+func runREPL(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("jsonlogic repl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dataFile := flags.String("data", "", "load the data from `file`")
+	historyFile := flags.String("history", defaultHistoryFile(), "keep the history of rules in `file`, empty for none")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitError
+	}
+
+	r := &repl{out: stdout, data: `{}`, historyFile: *historyFile}
+	if *dataFile != "" {
+		if err := r.load(*dataFile); err != nil {
+			fmt.Fprintf(stderr, "jsonlogic: %s\n", err)
+			return exitError
+		}
+	}
+	if err := r.loadHistory(); err != nil {
+		fmt.Fprintf(stderr, "jsonlogic: %s\n", err)
+		return exitError
+	}
+
+	fmt.Fprintln(stdout, `Enter a rule to evaluate, or :help for commands.`)
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for {
+		fmt.Fprint(stdout, "> ")
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == ":quit" || line == ":exit" {
+			break
+		}
+		if err := r.execute(line); err != nil {
+			fmt.Fprintf(stdout, "error: %s\n", err)
+		}
+	}
+	fmt.Fprintln(stdout)
+
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintf(stderr, "jsonlogic: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// execute runs a single line entered into the repl, either a command or a rule.
+func (r *repl) execute(line string) error {
+	if line == "" {
+		return nil
+	}
+	if !strings.HasPrefix(line, ":") {
+		return r.evaluate(line)
+	}
+
+	command, arg := line, ""
+	if i := strings.IndexAny(line, " \t"); i >= 0 {
+		command, arg = line[:i], strings.TrimSpace(line[i:])
+	}
+
+	switch command {
+	case ":help":
+		fmt.Fprintln(r.out, replHelp)
+	case ":load":
+		if arg == "" {
+			return errors.New(":load needs a file")
+		}
+		if err := r.load(arg); err != nil {
+			return err
+		}
+		fmt.Fprintf(r.out, "loaded %s\n", arg)
+	case ":data":
+		if arg != "" {
+			if !json.Valid([]byte(arg)) {
+				return errors.New("data is not valid json")
+			}
+			r.data = arg
+		}
+		fmt.Fprintln(r.out, r.data)
+	case ":trace":
+		r.trace = !r.trace
+		if r.trace {
+			fmt.Fprintln(r.out, "trace on")
+		} else {
+			fmt.Fprintln(r.out, "trace off")
+		}
+	case ":operators":
+		fmt.Fprintf(r.out, "built in: %s\n", strings.Join(jsonlogic.BuiltInOperators(), " "))
+		fmt.Fprintf(r.out, "registered: %s\n", strings.Join(jsonlogic.DefaultEngine.Operators(), " "))
+	case ":history":
+		for i, rule := range r.history {
+			fmt.Fprintf(r.out, "%4d  %s\n", i+1, rule)
+		}
+	default:
+		return fmt.Errorf("unknown command %s, enter :help for commands", command)
+	}
+	return nil
+}
+
+// evaluate evaluates a rule against the data and shows the result along with its Go type,
+// preceded by a trace of how it was evaluated when tracing.
+func (r *repl) evaluate(rule string) error {
+	if err := r.remember(rule); err != nil {
+		return err
+	}
+
+	program, err := jsonlogic.Compile(rule)
+	if err != nil {
+		return err
+	}
+
+	if !r.trace {
+		result, err := program.Evaluate(r.data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(r.out, describe(result))
+		return nil
+	}
+
+	// The trace is shown even when the rule fails, up to the operator which failed
+	result, trace, err := program.EvaluateWithTrace(r.data)
+	for _, line := range strings.Split(strings.TrimSuffix(trace.String(), "\n"), "\n") {
+		fmt.Fprintf(r.out, "  %s\n", line)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(r.out, describe(result))
+	return nil
+}
+
+// load replaces the data with the contents of a file.
+func (r *repl) load(name string) error {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(raw) {
+		return fmt.Errorf("%s is not valid json", name)
+	}
+	r.data = string(raw)
+	return nil
+}
+
+// loadHistory reads the rules entered in previous sessions.
+func (r *repl) loadHistory() error {
+	if r.historyFile == "" {
+		return nil
+	}
+
+	raw, err := os.ReadFile(r.historyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(raw), "\n") {
+		if line != "" {
+			r.history = append(r.history, line)
+		}
+	}
+	return nil
+}
+
+// remember adds a rule to the history.
+func (r *repl) remember(rule string) error {
+	r.history = append(r.history, rule)
+	if r.historyFile == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintln(file, rule)
+	return err
+}
+
+// describe formats a result as json followed by its Go type, such as: 1 (float64).
+func describe(result interface{}) string {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf("%v (%T)", result, result)
+	}
+	return fmt.Sprintf("%s (%T)", raw, result)
+}
+
+// defaultHistoryFile is where history is kept unless another file is given, in the home directory of the user.
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".jsonlogic_history")
+}
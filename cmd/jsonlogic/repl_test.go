@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	data := writeTemp(t, "data.json", `{"temp":100}`)
+	input := strings.Join([]string{
+		`{"<":[{"var":"temp"}, 110]}`,
+		`{"var":"missing"}`,
+		`{"nope":[]}`,
+		`:data {"temp":120}`,
+		`{"<":[{"var":"temp"}, 110]}`,
+	}, "\n")
+
+	code, stdout, _ := runCommand(t, input, "repl", "-data", data, "-history", "")
+	if code != exitOK {
+		t.Fatalf("repl should exit %d, instead exited %d", exitOK, code)
+	}
+
+	for _, expected := range []string{"true (bool)", "null (<nil>)", `error: unknown operator "nope"`, "false (bool)"} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("repl should show %s, instead showed %s", expected, stdout)
+		}
+	}
+}
+
+func TestREPLTrace(t *testing.T) {
+	data := writeTemp(t, "data.json", `{"a":2}`)
+	input := ":trace\n" + `{"and":[{">":[{"var":"a"}, 1]}, "yes"]}`
+
+	_, stdout, _ := runCommand(t, input, "repl", "-data", data, "-history", "")
+
+	for _, expected := range []string{"trace on", `  and(>(...) (true), "yes") was "yes"`, "    a (2) > 1 was true", `"yes" (string)`} {
+		if !strings.Contains(stdout, expected) {
+			t.Fatalf("repl should show %s, instead showed %s", expected, stdout)
+		}
+	}
+}
+
+func TestREPLTraceData(t *testing.T) {
+	input := `:data {"a":2}` + "\n:trace\n" + `{"var":"a"}`
+
+	_, stdout, _ := runCommand(t, input, "repl", "-history", "")
+
+	if !strings.Contains(stdout, "  var a was 2\n2 (float64)") {
+		t.Fatalf("repl should show the trace before the result, instead showed %s", stdout)
+	}
+}
+
+func TestREPLHistory(t *testing.T) {
+	history := filepath.Join(t.TempDir(), "history")
+
+	runCommand(t, `{"+":[1, 2]}`, "repl", "-history", history)
+	_, stdout, _ := runCommand(t, "{\"cat\":[\"a\"]}\n:history\n", "repl", "-history", history)
+
+	if !strings.Contains(stdout, `   1  {"+":[1, 2]}`) || !strings.Contains(stdout, `   2  {"cat":["a"]}`) {
+		t.Fatalf("history should be kept between sessions, instead showed %s", stdout)
+	}
+
+	raw, err := os.ReadFile(history)
+	if err != nil || string(raw) != "{\"+\":[1, 2]}\n{\"cat\":[\"a\"]}\n" {
+		t.Fatalf("history file should have each rule, instead has %q, %v", raw, err)
+	}
+}
+
+func TestREPLOperators(t *testing.T) {
+	_, stdout, _ := runCommand(t, ":operators", "repl", "-history", "")
+
+	if !strings.Contains(stdout, "built in: ! !! != ") || !strings.Contains(stdout, "registered:") {
+		t.Fatalf("repl should list the operators, instead showed %s", stdout)
+	}
+}
+
+func TestREPLCommandErrors(t *testing.T) {
+	input := ":load\n:load missing.json\n:data {\n:nope\n"
+
+	_, stdout, _ := runCommand(t, input, "repl", "-history", "")
+
+	if strings.Count(stdout, "error: ") != 4 {
+		t.Fatalf("each invalid command should show an error, instead showed %s", stdout)
+	}
+}
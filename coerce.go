@@ -0,0 +1,259 @@
+package jsonlogic
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// The coercion rules of JavaScript, which the comparison operators follow so rules give the same answers as
+// other JsonLogic implementations. See https://tc39.es/ecma262/#sec-islooselyequal and
+// https://tc39.es/ecma262/#sec-islessthan.
+
+// jsType is the JavaScript type a value is treated as.
+type jsType int
+
+const (
+	jsNull jsType = iota
+	jsBoolean
+	jsNumber
+	jsString
+	jsObject
+)
+
+// primitive normalizes a value and returns it along with the JavaScript type it is treated as.
+// Arrays, maps and structs are all objects.
+func primitive(value interface{}) (interface{}, jsType) {
+	value = normalize(reflect.ValueOf(value))
+	switch value.(type) {
+	case nil:
+		return nil, jsNull
+	case bool:
+		return value, jsBoolean
+	case float64:
+		return value, jsNumber
+	case string:
+		return value, jsString
+	}
+	return value, jsObject
+}
+
+// toPrimitive converts an object into a string the way JavaScript does, so an array becomes its elements joined
+// by commas and anything else "[object Object]". Other values are returned as they are.
+func toPrimitive(value interface{}) (interface{}, jsType) {
+	value, valueType := primitive(value)
+	if valueType != jsObject {
+		return value, valueType
+	}
+
+	items := toSlice(value)
+	if items == nil {
+		return "[object Object]", jsString
+	}
+
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		if item, itemType := primitive(item); itemType == jsNull {
+			parts = append(parts, "")
+		} else {
+			parts = append(parts, toString(item))
+		}
+	}
+	return strings.Join(parts, ","), jsString
+}
+
+// toNumber converts a value into a number the way JavaScript does, so null is 0, true is 1 and a string which
+// isn't a number is NaN.
+func toNumber(value interface{}) float64 {
+	value, valueType := toPrimitive(value)
+	switch valueType {
+	case jsBoolean:
+		if value.(bool) {
+			return 1
+		}
+		return 0
+	case jsNumber:
+		return value.(float64)
+	case jsString:
+		return stringToNumber(value.(string))
+	}
+	return 0
+}
+
+// stringToNumber parses a string as JavaScript does. Surrounding whitespace is ignored, an empty string is 0
+// and hexadecimal, octal and binary integers are accepted with their prefix.
+func stringToNumber(s string) float64 {
+	s = strings.TrimFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '\ufeff'
+	})
+
+	switch s {
+	case "":
+		return 0
+	case "Infinity", "+Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+		if base != 0 {
+			integer, ok := new(big.Int).SetString(s[2:], base)
+			if !ok || strings.ContainsAny(s[2:], "+-_") {
+				return math.NaN()
+			}
+			number, _ := new(big.Float).SetInt(integer).Float64()
+			return number
+		}
+	}
+
+	// ParseFloat accepts more than JavaScript, such as "inf" and underscores, so only allow decimal notation
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789.eE+-", r) {
+			return math.NaN()
+		}
+	}
+
+	number, err := strconv.ParseFloat(s, 64)
+	if err != nil && err.(*strconv.NumError).Err != strconv.ErrRange {
+		return math.NaN()
+	}
+	return number
+}
+
+// toString converts a primitive value into a string the way JavaScript does.
+func toString(value interface{}) string {
+	value, valueType := toPrimitive(value)
+	switch valueType {
+	case jsNull:
+		return "null"
+	case jsBoolean:
+		return strconv.FormatBool(value.(bool))
+	case jsNumber:
+		return numberToString(value.(float64))
+	}
+	return value.(string)
+}
+
+// numberToString formats a number as JavaScript does, which uses an exponent only for very large or small numbers.
+func numberToString(number float64) string {
+	switch {
+	case math.IsNaN(number):
+		return "NaN"
+	case math.IsInf(number, 1):
+		return "Infinity"
+	case math.IsInf(number, -1):
+		return "-Infinity"
+	case number == 0:
+		return "0"
+	}
+
+	abs := math.Abs(number)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+
+	// Go pads the exponent to two digits where JavaScript doesn't
+	s := strconv.FormatFloat(number, 'e', -1, 64)
+	mantissa, exponent := s[:strings.IndexByte(s, 'e')+2], s[strings.IndexByte(s, 'e')+2:]
+	return mantissa + strings.TrimLeft(exponent, "0")
+}
+
+// looseEqual implements JavaScript abstract equality, the '==' operator.
+func looseEqual(a interface{}, b interface{}) bool {
+	a, aType := primitive(a)
+	b, bType := primitive(b)
+
+	switch {
+	case aType == bType:
+		return strictEqual(a, b)
+	case aType == jsNull || bType == jsNull:
+		return false
+	case aType == jsNumber && bType == jsString:
+		return a.(float64) == stringToNumber(b.(string))
+	case aType == jsString && bType == jsNumber:
+		return stringToNumber(a.(string)) == b.(float64)
+	case aType == jsBoolean:
+		return looseEqual(toNumber(a), b)
+	case bType == jsBoolean:
+		return looseEqual(a, toNumber(b))
+	case aType == jsObject:
+		a, _ = toPrimitive(a)
+		return looseEqual(a, b)
+	case bType == jsObject:
+		b, _ = toPrimitive(b)
+		return looseEqual(a, b)
+	}
+	return false
+}
+
+// strictEqual implements JavaScript strict equality, the '===' operator. Arrays and objects are only equal to themselves.
+func strictEqual(a interface{}, b interface{}) bool {
+	a, aType := primitive(a)
+	b, bType := primitive(b)
+	if aType != bType {
+		return false
+	}
+
+	if aType != jsObject {
+		return a == b
+	}
+
+	aValue, bValue := reflect.ValueOf(a), reflect.ValueOf(b)
+	if aValue.Type() != bValue.Type() {
+		return false
+	}
+	switch aValue.Kind() {
+	case reflect.Slice:
+		// Empty slices may all share the same zero sized array, so only those with an array of their own are the same
+		return aValue.Cap() > 0 && aValue.Pointer() == bValue.Pointer() && aValue.Len() == bValue.Len()
+	case reflect.Map, reflect.Ptr:
+		return aValue.Pointer() == bValue.Pointer()
+	}
+	return false
+}
+
+// lessThan implements JavaScript abstract relational comparison. Two strings are compared by their UTF-16 code
+// units, anything else as numbers. The comparison is undefined, reported by ok being false, when either number is NaN.
+func lessThan(a interface{}, b interface{}) (less bool, ok bool) {
+	a, aType := toPrimitive(a)
+	b, bType := toPrimitive(b)
+
+	if aType == jsString && bType == jsString {
+		return compareUTF16(a.(string), b.(string)) < 0, true
+	}
+
+	x, y := toNumber(a), toNumber(b)
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return false, false
+	}
+	return x < y, true
+}
+
+// compareUTF16 compares two strings by their UTF-16 code units as JavaScript does, which only differs from
+// comparing their bytes for characters outside of the basic multilingual plane.
+func compareUTF16(a string, b string) int {
+	x, y := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			if x[i] < y[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(x) - len(y)
+}
@@ -0,0 +1,137 @@
+package jsonlogic
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComparisonCoercion(t *testing.T) {
+	// Expected results are those of the JavaScript implementation
+	rules := map[string]bool{
+		`{"==":[1, "1.0"]}`:                          true,
+		`{"==":[0, false]}`:                          true,
+		`{"==":[null, 0]}`:                           false,
+		`{"==":[null, null]}`:                        true,
+		`{"==":[null, false]}`:                       false,
+		`{"==":["", 0]}`:                             true,
+		`{"==":["0", false]}`:                        true,
+		`{"==":[true, "1"]}`:                         true,
+		`{"==":[" 1 ", 1]}`:                          true,
+		`{"==":["0x10", 16]}`:                        true,
+		`{"==":["1", "1.0"]}`:                        false,
+		`{"==":["inf", "Infinity"]}`:                 false,
+		`{"==":[[1], 1]}`:                            true,
+		`{"==":[[1, 2], "1,2"]}`:                     true,
+		`{"==":[[], false]}`:                         true,
+		`{"==":[[1], [1]]}`:                          false,
+		`{"==":[{"a":1, "b":2}, "[object Object]"]}`: true,
+		`{"!=":[0, false]}`:                          false,
+		`{"!=":[null, 0]}`:                           true,
+		`{"===":[1, 1]}`:                             true,
+		`{"===":[null, null]}`:                       true,
+		`{"===":[[1], [1]]}`:                         false,
+		`{"===":[0, false]}`:                         false,
+		`{"!==":[0, false]}`:                         true,
+		`{"<":["a", "b"]}`:                           true,
+		`{"<":["B", "a"]}`:                           true,
+		`{"<":["10", "9"]}`:                          true,
+		`{"<":[10, "9"]}`:                            false,
+		`{"<":[null, 1]}`:                            true,
+		`{"<":[false, true]}`:                        true,
+		`{"<":["abc", 1]}`:                           false,
+		`{"<":[1, "abc"]}`:                           false,
+		`{"<":[1, "5", 10]}`:                         true,
+		`{"<":["a", "b", "c"]}`:                      true,
+		`{">":["b", "a"]}`:                           true,
+		`{">":[1, 1]}`:                               false,
+		`{">":[1, "1"]}`:                             false,
+		`{">":[0, null]}`:                            false,
+		`{">":[2, "10"]}`:                            false,
+		`{">":["2", "10"]}`:                          true,
+		`{">=":["abc", 1]}`:                          false,
+		`{">=":[null, 0]}`:                           true,
+		`{">=":["b", "b"]}`:                          true,
+		`{"<=":[null, 0]}`:                           true,
+		`{"<=":[1, "abc"]}`:                          false,
+		`{"<=":["a", "a", "b"]}`:                     true,
+	}
+
+	for rule, expected := range rules {
+		result, err := Run(rule)
+		if err != nil {
+			t.Fatalf("rule %s should return %v, instead returned error %v", rule, expected, err)
+		}
+		if result != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v", rule, expected, result)
+		}
+	}
+}
+
+func TestMoreMoreEqualConsistent(t *testing.T) {
+	values := []interface{}{nil, false, true, 0.0, 1.0, "", "1", "a", []interface{}{}, []interface{}{1.0}}
+	for _, a := range values {
+		for _, b := range values {
+			if More(a, b) != Less(b, a) || MoreEqual(a, b) != LessEqual(b, a) {
+				t.Fatalf("comparing %#v with %#v should be consistent", a, b)
+			}
+			if More(a, b) && !MoreEqual(a, b) {
+				t.Fatalf("%#v > %#v should imply %#v >= %#v", a, b, a, b)
+			}
+		}
+	}
+}
+
+func TestStrictEqualIdentity(t *testing.T) {
+	items := []interface{}{1.0}
+	if !strictEqual(items, items) || !looseEqual(items, items) {
+		t.Fatal("an array should be equal to itself")
+	}
+
+	empty, other := make([]interface{}, 0), make([]interface{}, 0)
+	if strictEqual(empty, other) || looseEqual(empty, other) {
+		t.Fatal("separate empty arrays should not be equal")
+	}
+}
+
+func TestStringToNumber(t *testing.T) {
+	numbers := map[string]float64{
+		"":          0,
+		" 12 ":      12,
+		"1.5e3":     1500,
+		".5":        0.5,
+		"0x1F":      31,
+		"0b101":     5,
+		"0o17":      15,
+		"-Infinity": math.Inf(-1),
+		"1e400":     math.Inf(1),
+	}
+	for s, expected := range numbers {
+		if number := stringToNumber(s); number != expected {
+			t.Fatalf("%q should be %v, instead was %v", s, expected, number)
+		}
+	}
+
+	for _, s := range []string{"abc", "1_000", "inf", "NaN", "0x", "-0x10", "1e", "1 2"} {
+		if number := stringToNumber(s); !math.IsNaN(number) {
+			t.Fatalf("%q should be NaN, instead was %v", s, number)
+		}
+	}
+}
+
+func TestNumberToString(t *testing.T) {
+	numbers := map[float64]string{
+		1:            "1",
+		-0.5:         "-0.5",
+		1e21:         "1e+21",
+		1.5e-7:       "1.5e-7",
+		1e-6:         "0.000001",
+		123456789012: "123456789012",
+		math.NaN():   "NaN",
+		math.Inf(1):  "Infinity",
+	}
+	for number, expected := range numbers {
+		if s := numberToString(number); s != expected {
+			t.Fatalf("%v should be %q, instead was %q", number, expected, s)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Program is a rule compiled into a tree of nodes so it only has to be parsed once.
+// A Program holds no state between evaluations so it can be evaluated any number of times and shared across goroutines.
+type Program struct {
+	root   node
+	engine *Engine
+
+	// optimized is the rule rewritten to be quicker to evaluate, nil if it couldn't be, which is used while none of
+	// the built in operators it relies on are replaced by custom operators. See optimize.
+	optimized node
+	builtIns  []string
+}
+
+// evaluation holds the state shared by every node during a single evaluation of a program.
+type evaluation struct {
+	engine *Engine
+	ctx    context.Context
+	done   <-chan struct{}
+	limits limits
+
+	// depth is the number of operators currently being evaluated and operations the total evaluated so far
+	depth      int
+	operations int
+
+	// trace is the trace of the operator currently being evaluated when tracing, otherwise nil
+	trace *Trace
+}
+
+// node is a single compiled value or operation within a rule.
+type node interface {
+	eval(ev *evaluation, data interface{}) (interface{}, error)
+}
+
+// literalNode is a string, number, boolean or null within a rule.
+type literalNode struct {
+	value interface{}
+}
+
+// arrayNode is an array within a rule, each element of which is evaluated.
+type arrayNode struct {
+	items []node
+	at    *location
+}
+
+// objectNode is an object which isn't an operation, as it doesn't have exactly one key, so is treated as data.
+type objectNode struct {
+	raw []byte
+}
+
+// operatorNode is an operation along with its arguments. The raw arguments are kept for custom operators.
+type operatorNode struct {
+	key  string
+	args []node
+	rule []byte
+	at   *location
+}
+
+// location is where a node is within a rule, linked to the location of the operator or array it is within so the
+// path of each node of a deeply nested rule is only built when it is needed, such as for an error.
+type location struct {
+	parent *location
+	// key is that of an operator when named, otherwise the location is of the argument or element at index
+	key   string
+	index int
+	named bool
+}
+
+// operator returns the location of an operator with the key within l.
+func (l *location) operator(key string) *location {
+	return &location{parent: l, key: key, named: true}
+}
+
+// argument returns the location of the argument or element at index within l.
+func (l *location) argument(index int) *location {
+	return &location{parent: l, index: index}
+}
+
+// String returns the path of the location, such as 'and[1].<' for the '<' within the second argument of 'and',
+// which is empty for the root of the rule.
+func (l *location) String() string {
+	segments := make([]*location, 0)
+	for at := l; at != nil; at = at.parent {
+		segments = append(segments, at)
+	}
+
+	b := &strings.Builder{}
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := segments[i]
+		if !segment.named {
+			fmt.Fprintf(b, "[%d]", segment.index)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(segment.key)
+	}
+	return b.String()
+}
+
+// path is the path of the operator within the rule.
+func (n *operatorNode) path() string {
+	return n.at.String()
+}
+
+// path is the path of the array within the rule.
+func (n *arrayNode) path() string {
+	return n.at.String()
+}
+
+// Compile parses a rule into a Program which can be evaluated many times against different data.
+func Compile(rule string) (*Program, error) {
+	return DefaultEngine.Compile(rule)
+}
+
+// Compile parses a rule into a Program which evaluates using the operators of the engine.
+// A rule which isn't valid json returns a *ParseError.
+func (e *Engine) Compile(rule string) (*Program, error) {
+	return e.compile(context.Background(), rule, limits{})
+}
+
+// compile parses a rule into a Program which is to be evaluated within limits, stopping with the error of ctx once it
+// is done. A rule nested deeper than the limits allow fails without being evaluated, and a rule which is to be
+// evaluated within any limits isn't optimized as it is evaluated as written.
+func (e *Engine) compile(ctx context.Context, rule string, limits limits) (*Program, error) {
+	c := newCompiler(ctx, []byte(rule), limits)
+	root, err := c.value(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.end(); err != nil {
+		return nil, err
+	}
+
+	program := &Program{root: root, engine: e}
+	if !limits.limited() {
+		program.optimized, program.builtIns = optimize(ctx, e, root)
+	}
+	return program, nil
+}
+
+// Evaluate runs the compiled rule against optional json data.
+func (p *Program) Evaluate(data string) (interface{}, error) {
+	return p.EvaluateContext(context.Background(), data)
+}
+
+// EvaluateContext runs the compiled rule against optional json data, stopping with the error of ctx once it is done.
+// Options limit the resources the evaluation may use.
+func (p *Program) EvaluateContext(ctx context.Context, data string, options ...Option) (interface{}, error) {
+	return p.evaluate(newEvaluation(ctx, p.engine, options), data)
+}
+
+// evaluate runs the compiled rule against optional json data within an evaluation.
+func (p *Program) evaluate(ev *evaluation, data string) (interface{}, error) {
+	raw, err := parseData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.run(ev, raw)
+}
+
+// run evaluates the rule against data, failing straight away if the context of the evaluation is already done
+// as the optimized rule may have no operators left to notice.
+func (p *Program) run(ev *evaluation, data interface{}) (interface{}, error) {
+	if err := ev.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.rule(ev).eval(ev, data)
+}
+
+// rule returns the optimized rule unless the evaluation should see every operator of the rule as written, as when it
+// is traced or limited, or an operator the optimized rule relies on has since been replaced.
+func (p *Program) rule(ev *evaluation) node {
+	if p.optimized == nil || ev.trace != nil || ev.limits.limited() || ev.engine.replaces(p.builtIns) {
+		return p.root
+	}
+	return p.optimized
+}
+
+// parseData checks json data passed to a program, where no data is an empty object.
+func parseData(data string) (jsonData, error) {
+
+	// Ensure data is object
+	if data == `` {
+		data = `{}`
+	}
+
+	// Unicode &
+	data = strings.ReplaceAll(data, `\u0026`, `&`)
+
+	if !json.Valid([]byte(data)) {
+		return nil, &ParseError{Err: errInvalidData}
+	}
+
+	return jsonData(data), nil
+}
+
+// EvaluateValue runs the compiled rule against data which has already been decoded or built in Go, such as
+// map[string]interface{}, slices and structs. Struct fields are found by their json tag, or their name without one.
+func (p *Program) EvaluateValue(data interface{}) (interface{}, error) {
+	return p.run(newEvaluation(context.Background(), p.engine, nil), data)
+}
+
+// compiler compiles a rule in a single pass over its json. The raw json of each operator is sliced from the rule
+// rather than copied, as it is only needed by custom operators registered with AddOperator.
+type compiler struct {
+	rule    []byte
+	decoder *json.Decoder
+	ctx     context.Context
+	done    <-chan struct{}
+	limits  limits
+
+	// depth is the number of objects currently being compiled
+	depth int
+}
+
+func newCompiler(ctx context.Context, rule []byte, limits limits) *compiler {
+	decoder := json.NewDecoder(bytes.NewReader(rule))
+	decoder.UseNumber()
+	return &compiler{rule: rule, decoder: decoder, ctx: ctx, done: ctx.Done(), limits: limits}
+}
+
+// enter is called before the arguments of the operator at a location are compiled, returning an error if compiling
+// should stop. Each successful call must be followed by a call to leave once they have been compiled.
+func (c *compiler) enter(at *location) error {
+	select {
+	case <-c.done:
+		return c.ctx.Err()
+	default:
+	}
+
+	if c.limits.maxDepth > 0 && c.depth >= c.limits.maxDepth {
+		return &BudgetError{Limit: "depth", Max: c.limits.maxDepth, Path: at.String()}
+	}
+	c.depth++
+	return nil
+}
+
+// leave is called once the arguments of an operator have been compiled.
+func (c *compiler) leave() {
+	c.depth--
+}
+
+// offset returns the offset within the rule of the next value, skipping the separators before it.
+func (c *compiler) offset() int {
+	offset := int(c.decoder.InputOffset())
+	for offset < len(c.rule) && strings.IndexByte(" \t\r\n:,", c.rule[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// end checks only whitespace follows the rule once it has been compiled.
+func (c *compiler) end() error {
+	offset := int(c.decoder.InputOffset())
+	for offset < len(c.rule) && strings.IndexByte(" \t\r\n", c.rule[offset]) >= 0 {
+		offset++
+	}
+	if offset < len(c.rule) {
+		return &ParseError{Err: fmt.Errorf("invalid character %q after the rule at offset %d", c.rule[offset], offset)}
+	}
+	return nil
+}
+
+// token reads the next token of the rule, where the rule ending early is an unexpected EOF.
+func (c *compiler) token(at *location) (json.Token, error) {
+	token, err := c.decoder.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, &ParseError{Path: at.String(), Err: err}
+	}
+	return token, nil
+}
+
+// value compiles the next json value of the rule found at a location.
+func (c *compiler) value(at *location) (node, error) {
+	start := c.offset()
+	token, err := c.token(at)
+	if err != nil {
+		return nil, err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			items, err := c.array(at)
+			if err != nil {
+				return nil, err
+			}
+			return &arrayNode{items: items, at: at}, nil
+		}
+		return c.object(at, start)
+	case json.Number:
+		number, err := strconv.ParseFloat(string(token), 64)
+		if err != nil {
+			return nil, &ParseError{Path: at.String(), Err: err}
+		}
+		return &literalNode{value: number}, nil
+	}
+	return &literalNode{value: token}, nil
+}
+
+// array compiles the elements of an array found at a location once its opening bracket has been read.
+func (c *compiler) array(at *location) ([]node, error) {
+	items := make([]node, 0)
+	for c.decoder.More() {
+		item, err := c.value(at.argument(len(items)))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if _, err := c.token(at); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// object compiles an object starting at start once its opening brace has been read. An object with a single key is
+// an operation, anything else is kept as data.
+func (c *compiler) object(at *location, start int) (node, error) {
+	keys := 0
+	var operator *operatorNode
+	for c.decoder.More() {
+		token, err := c.token(at)
+		if err != nil {
+			return nil, err
+		}
+		keys++
+
+		key, _ := token.(string)
+		operator = &operatorNode{key: key, at: at.operator(key)}
+		if err := c.enter(operator.at); err != nil {
+			return nil, err
+		}
+		argsStart := c.offset()
+		if operator.args, err = c.arguments(operator.at); err != nil {
+			return nil, err
+		}
+		c.leave()
+		// Strings keep their quotes so custom operators can tell them apart
+		operator.rule = c.rule[argsStart:c.decoder.InputOffset()]
+	}
+
+	if _, err := c.token(at); err != nil {
+		return nil, err
+	}
+
+	if keys != 1 {
+		return &objectNode{raw: c.rule[start:c.decoder.InputOffset()]}, nil
+	}
+	return operator, nil
+}
+
+// arguments compiles the arguments of an operation, where a single argument doesn't need to be wrapped in an array.
+func (c *compiler) arguments(at *location) ([]node, error) {
+	if offset := c.offset(); offset < len(c.rule) && c.rule[offset] == '[' {
+		if _, err := c.token(at); err != nil {
+			return nil, err
+		}
+		return c.array(at)
+	}
+
+	arg, err := c.value(at.argument(0))
+	if err != nil {
+		return nil, err
+	}
+	return []node{arg}, nil
+}
+
+// compileArguments compiles the raw json of the arguments of an operation found at a location.
+func compileArguments(rule []byte, at *location) ([]node, error) {
+	return newCompiler(context.Background(), rule, limits{}).arguments(at)
+}
+
+// evalArguments resolves all of the arguments of an operation in order.
+func evalArguments(ev *evaluation, args []node, data interface{}) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		value, err := arg.eval(ev, data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (n *literalNode) eval(ev *evaluation, data interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *arrayNode) eval(ev *evaluation, data interface{}) (interface{}, error) {
+	items, err := evalArguments(ev, n.items, data)
+	if err != nil {
+		return nil, err
+	}
+	return items, ev.checkSize(items, n.at)
+}
+
+func (n *objectNode) eval(ev *evaluation, data interface{}) (interface{}, error) {
+	// Decoded on each evaluation so callers are free to modify the result
+	var value map[string]interface{}
+	err := json.Unmarshal(n.raw, &value)
+	return value, err
+}
+
+func (n *operatorNode) eval(ev *evaluation, data interface{}) (interface{}, error) {
+	if err := ev.enter(n.at); err != nil {
+		return nil, err
+	}
+	defer ev.leave()
+
+	if ev.trace != nil {
+		return n.evalTraced(ev, data)
+	}
+
+	result, err := n.apply(ev, data)
+	return n.finish(ev, result, err)
+}
+
+// finish checks the result of the operator against the limits of the evaluation.
+func (n *operatorNode) finish(ev *evaluation, result interface{}, err error) (interface{}, error) {
+	if err == nil && !passThrough[n.key] {
+		err = ev.checkSize(result, n.at)
+	}
+	return result, err
+}
+
+// apply evaluates the operator, which is a custom operator of the engine if there is one, otherwise a built in one.
+func (n *operatorNode) apply(ev *evaluation, data interface{}) (interface{}, error) {
+	// Custom operators take precedence over built in ones
+	if operation, ok := ev.engine.operation(n.key); ok {
+		ctx := &Context{ev: ev, data: data}
+		args := make([]Argument, 0, len(n.args))
+		for _, arg := range n.args {
+			args = append(args, Argument{ctx: ctx, node: arg})
+		}
+		result, err := operation(ctx, args)
+		return result, withPath(err, n.key, n.at)
+	}
+
+	if operation, ok := ev.engine.operator(n.key); ok {
+		return operation(string(n.rule), dataString(data)), nil
+	}
+
+	result, err := runOperator(ev, n.key, n.args, data)
+	return result, withPath(err, n.key, n.at)
+}
@@ -0,0 +1,186 @@
+package jsonlogic
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cast"
+)
+
+func TestCompile(t *testing.T) {
+	program, err := Compile(`{"<" : [ { "var" : "temp" }, 110 ]}`)
+	if err != nil {
+		t.Fatalf("rule should compile, instead returned %s", err)
+	}
+
+	for _, temp := range []int{100, 120, 50} {
+		result, err := program.Evaluate(`{"temp":` + cast.ToString(temp) + `}`)
+		if err != nil {
+			t.Fatalf("rule should evaluate, instead returned %s", err)
+		}
+
+		if cast.ToBool(result) != (temp < 110) {
+			t.Fatalf("rule should return %t for %d, instead returned %v", temp < 110, temp, result)
+		}
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	rules := []string{
+		``,
+		`{"==":[1,`,
+		`{"==":[1, 2}`,
+		`{"var":"a"}}`,
+		`{"var":"a"} junk`,
+		`1 2`,
+	}
+
+	for _, rule := range rules {
+		if _, err := Compile(rule); err == nil {
+			t.Fatalf("rule %s should fail to compile", rule)
+		}
+	}
+}
+
+func TestCompileTrailingInput(t *testing.T) {
+	_, err := Compile("{\"var\":\"a\"} \n x")
+
+	parseErr, ok := err.(*ParseError)
+	if !ok || parseErr.Err.Error() != `invalid character 'x' after the rule at offset 14` {
+		t.Fatalf("rule should fail at the input after it, instead returned %v", err)
+	}
+
+	if _, err := Compile("{\"var\":\"a\"} \r\n\t"); err != nil {
+		t.Fatalf("whitespace after the rule should be allowed, instead returned %v", err)
+	}
+}
+
+func TestCompileLiteral(t *testing.T) {
+	program, err := Compile(`[1, "two", {"var":"three"}]`)
+	if err != nil {
+		t.Fatalf("rule should compile, instead returned %s", err)
+	}
+
+	result, _ := program.Evaluate(`{"three":3}`)
+	target := []interface{}{1.0, "two", 3.0}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [1,two,3], instead returned %v", result)
+	}
+}
+
+func TestCompileObjectData(t *testing.T) {
+	program, err := Compile(`{"merge":[ [{"a":1, "b":2}], [{}] ]}`)
+	if err != nil {
+		t.Fatalf("rule should compile, instead returned %s", err)
+	}
+
+	result, _ := program.Evaluate(``)
+	target := []interface{}{map[string]interface{}{"a": 1.0, "b": 2.0}, map[string]interface{}{}}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return objects as data, instead returned %v", result)
+	}
+}
+
+func TestCompileEscapedString(t *testing.T) {
+	result, _ := Run(`{"cat":["say \"hi\"", "!"]}`)
+
+	if result != `say "hi"!` {
+		t.Fatalf("rule should return say \"hi\"!, instead returned %v", result)
+	}
+}
+
+func TestCompileRawArguments(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("raw", func(rule string, data string) interface{} {
+		return rule
+	})
+
+	rules := map[string]string{
+		`{"raw":"a"}`:                           `"a"`,
+		`{"raw" : [1, {"var":"x"}] }`:           `[1, {"var":"x"}]`,
+		`{"cat":[{"raw":{"a":1, "b":[true]}}]}`: `{"a":1, "b":[true]}`,
+	}
+
+	for rule, expected := range rules {
+		result, err := engine.Run(rule)
+		if err != nil || result != expected {
+			t.Fatalf("rule %s should pass %s to the operator, instead returned %v, %v", rule, expected, result, err)
+		}
+	}
+}
+
+func TestCompileDeepRule(t *testing.T) {
+	depth := 5000
+	rule := strings.Repeat(`{"!":`, depth) + `"\x"` + strings.Repeat(`}`, depth)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Compile(rule)
+	runtime.ReadMemStats(&after)
+
+	parseErr, ok := err.(*ParseError)
+	if !ok || parseErr.Path != strings.Repeat("![0].", depth-1)+"![0]" {
+		t.Fatalf("rule should fail to compile at the innermost argument, instead returned %v", err)
+	}
+
+	// Copying the arguments or path of each operator would allocate hundreds of megabytes
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 32<<20 {
+		t.Fatalf("compiling should allocate in proportion to the rule, instead allocated %d bytes", allocated)
+	}
+}
+
+func TestProgramConcurrent(t *testing.T) {
+	program, err := Compile(`{"map":[ {"var":"integers"}, {"*":[{"var":""}, {"var":""}]} ]}`)
+	if err != nil {
+		t.Fatalf("rule should compile, instead returned %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data := `{"integers":[` + cast.ToString(i) + `]}`
+			result, err := program.Evaluate(data)
+			if err != nil {
+				t.Errorf("rule should evaluate, instead returned %s", err)
+				return
+			}
+
+			target := []interface{}{float64(i * i)}
+			if !reflect.DeepEqual(result, target) {
+				t.Errorf("rule should return %v, instead returned %v", target, result)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkApply(b *testing.B) {
+	rule := `{ "and" : [
+		{"<" : [ { "var" : "temp" }, 110 ]},
+		{"==" : [ { "var" : "pie.filling" }, "apple" ] }
+	] }`
+	data := `{ "temp" : 100, "pie" : { "filling" : "apple" } }`
+
+	for i := 0; i < b.N; i++ {
+		Apply(rule, data)
+	}
+}
+
+func BenchmarkProgramEvaluate(b *testing.B) {
+	program, _ := Compile(`{ "and" : [
+		{"<" : [ { "var" : "temp" }, 110 ]},
+		{"==" : [ { "var" : "pie.filling" }, "apple" ] }
+	] }`)
+	data := `{ "temp" : 100, "pie" : { "filling" : "apple" } }`
+
+	for i := 0; i < b.N; i++ {
+		program.Evaluate(data)
+	}
+}
@@ -0,0 +1,138 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// knownDeviations are the cases of the shared test suite which are known to fail, keyed by the rule and data
+// of the case, along with the reason why. Remove a case once it passes.
+var knownDeviations = map[string]string{}
+
+// conformanceCase is a single rule from the shared test suite, along with its data and expected result.
+type conformanceCase struct {
+	section  string
+	rule     string
+	data     string
+	expected interface{}
+}
+
+// key identifies the case within knownDeviations.
+func (c conformanceCase) key() string {
+	return c.rule + " " + c.data
+}
+
+// loadConformanceCases reads the shared JsonLogic test suite, vendored from https://jsonlogic.com/tests.json, along with
+// sections of cases added to it at the end, whose names say they were added.
+// The suite is an array of cases, each [rule, data, expected], with strings in between naming the section that follows.
+func loadConformanceCases(t *testing.T) []conformanceCase {
+	raw, err := os.ReadFile("testdata/tests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := make([]conformanceCase, 0, len(entries))
+	section := ""
+	for _, entry := range entries {
+		var comment string
+		if json.Unmarshal(entry, &comment) == nil {
+			section = strings.TrimSpace(strings.TrimPrefix(comment, "#"))
+			continue
+		}
+
+		var parts []json.RawMessage
+		if err := json.Unmarshal(entry, &parts); err != nil || len(parts) != 3 {
+			t.Fatalf("malformed case %s", entry)
+		}
+
+		var expected interface{}
+		if err := json.Unmarshal(parts[2], &expected); err != nil {
+			t.Fatal(err)
+		}
+
+		cases = append(cases, conformanceCase{
+			section:  section,
+			rule:     compactJSON(t, parts[0]),
+			data:     compactJSON(t, parts[1]),
+			expected: expected,
+		})
+	}
+	return cases
+}
+
+// compactJSON re-encodes json without whitespace so each case has a single key.
+func compactJSON(t *testing.T, raw json.RawMessage) string {
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatal(err)
+	}
+	compact := &strings.Builder{}
+	encoder := json.NewEncoder(compact)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(compact.String())
+}
+
+// TestConformance runs the shared test suite, requiring results of exactly the expected type, and logs how many
+// cases of each section pass. Run with -v to see the matrix.
+func TestConformance(t *testing.T) {
+	type tally struct{ passed, failed, known int }
+	sections := make([]string, 0)
+	tallies := make(map[string]*tally)
+
+	for _, c := range loadConformanceCases(t) {
+		if _, ok := tallies[c.section]; !ok {
+			sections = append(sections, c.section)
+			tallies[c.section] = &tally{}
+		}
+		counts := tallies[c.section]
+
+		result, err := Apply(c.rule, c.data)
+		passed := err == nil && reflect.DeepEqual(result, c.expected)
+		reason, known := knownDeviations[c.key()]
+
+		switch {
+		case passed && known:
+			counts.passed++
+			t.Errorf("%s with data %s now passes, remove it from knownDeviations (%s)", c.rule, c.data, reason)
+		case passed:
+			counts.passed++
+		case known:
+			counts.known++
+		default:
+			counts.failed++
+			t.Errorf("%s with data %s should return %#v, instead returned %#v, %v", c.rule, c.data, c.expected, result, err)
+		}
+	}
+
+	width := len("section")
+	for _, section := range sections {
+		if len(section) > width {
+			width = len(section)
+		}
+	}
+
+	total := tally{}
+	matrix := &strings.Builder{}
+	fmt.Fprintf(matrix, "%-*s %6s %6s %6s\n", width, "section", "pass", "fail", "known")
+	for _, section := range sections {
+		counts := tallies[section]
+		fmt.Fprintf(matrix, "%-*s %6d %6d %6d\n", width, section, counts.passed, counts.failed, counts.known)
+		total.passed += counts.passed
+		total.failed += counts.failed
+		total.known += counts.known
+	}
+	fmt.Fprintf(matrix, "%-*s %6d %6d %6d", width, "total", total.passed, total.failed, total.known)
+	t.Log("\n" + matrix.String())
+}
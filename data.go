@@ -0,0 +1,222 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/spf13/cast"
+)
+
+// jsonData is data passed to Apply as json, which is scanned on each lookup rather than decoded up front.
+// Any other data is a Go value such as one passed to ApplyValue or an element within 'map'.
+type jsonData []byte
+
+// resolveVar implements the 'var' operator for either json or Go data.
+func resolveVar(key interface{}, fallback interface{}, data interface{}) interface{} {
+	if raw, ok := data.(jsonData); ok {
+		return Var(key, fallback, string(raw))
+	}
+
+	if cast.ToString(key) == "" {
+		return normalize(reflect.ValueOf(data))
+	}
+
+	value, ok := lookup(data, key)
+	if !ok || value == nil {
+		return fallback
+	}
+	return value
+}
+
+// isMissing reports whether the key is absent, null or an empty string within either json or Go data.
+func isMissing(key interface{}, data interface{}) bool {
+	if raw, ok := data.(jsonData); ok {
+		value, dataType := jsonGet(raw, key)
+		return dataType == jsonparser.NotExist || dataType == jsonparser.Null || (dataType == jsonparser.String && len(value) == 0)
+	}
+
+	value, ok := lookup(data, key)
+	return !ok || value == nil || value == ""
+}
+
+// dataString returns data as json for custom operators registered with AddOperator.
+func dataString(data interface{}) string {
+	if raw, ok := data.(jsonData); ok {
+		return string(raw)
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return `null`
+	}
+	return string(raw)
+}
+
+// lookup finds a var key, either a dot notation string or a numeric index, within Go data by walking maps,
+// slices, arrays and structs.
+func lookup(data interface{}, key interface{}) (interface{}, bool) {
+	current := reflect.ValueOf(data)
+	for _, segment := range keySegments(key) {
+		next, ok := child(current, segment)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	return normalize(current), true
+}
+
+// jsonGet finds a var key, either a dot notation string or a numeric index, within json data.
+// A segment of the key is an index when the value it is applied to is an array.
+func jsonGet(data []byte, key interface{}) ([]byte, jsonparser.ValueType) {
+	value, dataType, _, err := jsonparser.Get(data)
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+
+	for _, segment := range keySegments(key) {
+		switch dataType {
+		case jsonparser.Object:
+		case jsonparser.Array:
+			if _, err := strconv.Atoi(segment); err != nil {
+				return nil, jsonparser.NotExist
+			}
+			segment = "[" + segment + "]"
+		default:
+			return nil, jsonparser.NotExist
+		}
+
+		value, dataType, _, err = jsonparser.Get(value, segment)
+		if err != nil {
+			return nil, jsonparser.NotExist
+		}
+	}
+	return value, dataType
+}
+
+// keySegments splits a var key into the keys and indexes it is made of.
+func keySegments(key interface{}) []string {
+	if index, ok := key.(float64); ok {
+		return []string{strconv.FormatFloat(index, 'f', -1, 64)}
+	}
+	return strings.Split(cast.ToString(key), ".")
+}
+
+// child returns the element of a map, slice, array or struct named by a single segment of a var key.
+func child(value reflect.Value, segment string) (reflect.Value, bool) {
+	value = indirect(value)
+
+	switch value.Kind() {
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		element := value.MapIndex(reflect.ValueOf(segment).Convert(value.Type().Key()))
+		return element, element.IsValid()
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= value.Len() {
+			return reflect.Value{}, false
+		}
+		return value.Index(index), true
+	case reflect.Struct:
+		return field(value, segment)
+	}
+
+	return reflect.Value{}, false
+}
+
+// field finds the exported field of a struct with the json name, following the rules of encoding/json:
+// a json tag name is used in place of the field name, an exact match is preferred over one without regard to case,
+// and the fields of embedded structs are promoted.
+func field(value reflect.Value, name string) (reflect.Value, bool) {
+	var fold reflect.Value
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		structField := valueType.Field(i)
+		tag := structField.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		tagName := strings.Split(tag, ",")[0]
+		if structField.Anonymous && tagName == "" {
+			if embedded, ok := child(value.Field(i), name); ok {
+				return embedded, true
+			}
+			continue
+		}
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		fieldName := tagName
+		if fieldName == "" {
+			fieldName = structField.Name
+		}
+
+		switch {
+		case fieldName == name:
+			return value.Field(i), true
+		case !fold.IsValid() && strings.EqualFold(fieldName, name):
+			fold = value.Field(i)
+		}
+	}
+
+	return fold, fold.IsValid()
+}
+
+// toSlice returns the elements of any slice or array, such as a slice of structs passed to ApplyValue, or nil for anything else.
+func toSlice(data interface{}) []interface{} {
+	if items, ok := data.([]interface{}); ok {
+		return items
+	}
+
+	value := indirect(reflect.ValueOf(data))
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return nil
+	}
+
+	items := make([]interface{}, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		items = append(items, value.Index(i).Interface())
+	}
+	return items
+}
+
+// indirect follows pointers and interfaces to the value they hold.
+func indirect(value reflect.Value) reflect.Value {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// normalize converts Go values into the types operators work with, the same as those decoded from json,
+// so numbers become float64 and named string and bool types their underlying type.
+func normalize(value reflect.Value) interface{} {
+	value = indirect(value)
+
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.String:
+		return value.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	}
+	return value.Interface()
+}
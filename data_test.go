@@ -0,0 +1,210 @@
+package jsonlogic
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type testLine struct {
+	SKU      string  `json:"sku"`
+	Quantity int     `json:"qty"`
+	Price    float64 `json:"price,omitempty"`
+	Secret   string  `json:"-"`
+}
+
+type testAudit struct {
+	CreatedBy string
+}
+
+type testOrder struct {
+	testAudit
+	ID       string     `json:"id"`
+	Lines    []testLine `json:"lines"`
+	Customer *struct {
+		Name string `json:"name"`
+	} `json:"customer"`
+	Flags map[string]bool `json:"flags"`
+}
+
+func TestApplyValueMap(t *testing.T) {
+	rule := `{"and":[{"==":[{"var":"pie.filling"}, "apple"]}, {"<":[{"var":"temp"}, 110]}]}`
+	data := map[string]interface{}{
+		"temp": 100,
+		"pie":  map[string]interface{}{"filling": "apple"},
+	}
+
+	result, err := ApplyValue(rule, data)
+
+	if err != nil || result != true {
+		t.Fatalf("rule should return true, instead returned %v, %v", result, err)
+	}
+}
+
+func TestApplyValueSliceIndex(t *testing.T) {
+	result, _ := ApplyValue(`{"var":"items.1"}`, map[string]interface{}{"items": []string{"zero", "one"}})
+	if result != "one" {
+		t.Fatalf("rule should return one, instead returned %v", result)
+	}
+
+	result, _ = ApplyValue(`{"var":1}`, []interface{}{"zero", "one"})
+	if result != "one" {
+		t.Fatalf("rule should return one, instead returned %v", result)
+	}
+}
+
+func TestApplyValueStruct(t *testing.T) {
+	order := &testOrder{
+		testAudit: testAudit{CreatedBy: "admin"},
+		ID:        "A1",
+		Lines:     []testLine{{SKU: "a", Quantity: 2, Price: 3.5, Secret: "x"}, {SKU: "b", Quantity: 1, Price: 10}},
+		Flags:     map[string]bool{"vip": true},
+	}
+	order.Customer = &struct {
+		Name string `json:"name"`
+	}{Name: "Bruce"}
+
+	rules := map[string]interface{}{
+		`{"var":"id"}`:                                        "A1",
+		`{"var":"customer.name"}`:                             "Bruce",
+		`{"var":"lines.0.qty"}`:                               2.0,
+		`{"var":"lines.1.price"}`:                             10.0,
+		`{"var":"flags.vip"}`:                                 true,
+		`{"var":"createdby"}`:                                 "admin",
+		`{"var":"lines.0.Secret"}`:                            nil,
+		`{"var":["ID", "fallback"]}`:                          "A1",
+		`{"var":["missing", "fallback"]}`:                     "fallback",
+		`{"all":[{"var":"lines"}, {">":[{"var":"qty"}, 0]}]}`: true,
+		`{"map":[{"var":"lines"}, {"var":"sku"}]}`:            []interface{}{"a", "b"},
+		`{"reduce":[{"var":"lines"}, {"+":[{"*":[{"var":"current.qty"}, {"var":"current.price"}]}, {"var":"accumulator"}]}, 0]}`: 17.0,
+		`{"missing":["id", "customer.name", "customer.email"]}`:                                                                  []interface{}{"customer.email"},
+	}
+
+	for rule, target := range rules {
+		result, err := ApplyValue(rule, order)
+		if err != nil || !reflect.DeepEqual(result, target) {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, target, result, err)
+		}
+	}
+}
+
+func TestApplyValueNilPointer(t *testing.T) {
+	result, err := ApplyValue(`{"var":["customer.name", "nobody"]}`, &testOrder{})
+
+	if err != nil || result != "nobody" {
+		t.Fatalf("rule should return nobody, instead returned %v, %v", result, err)
+	}
+}
+
+func TestApplyValueCustomOperator(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("raw", func(rule string, data string) interface{} {
+		return data
+	})
+
+	result, _ := engine.ApplyValue(`{"raw":[]}`, map[string]int{"a": 1})
+	if result != `{"a":1}` {
+		t.Fatalf("rule should return the data as json, instead returned %v", result)
+	}
+}
+
+func TestApplyValueTypedSlices(t *testing.T) {
+	data := map[string]interface{}{
+		"ints":    []int{1, 2},
+		"keys":    []string{"a", "b"},
+		"letters": [2]string{"x", "y"},
+		"a":       1,
+	}
+
+	rules := map[string]interface{}{
+		`{"in":[1, {"var":"ints"}]}`:                    true,
+		`{"in":[3, {"var":"ints"}]}`:                    false,
+		`{"in":["y", {"var":"letters"}]}`:               true,
+		`{"merge":[{"var":"ints"}, [3]]}`:               []interface{}{1, 2, 3.0},
+		`{"merge":[{"var":"keys"}, {"var":"letters"}]}`: []interface{}{"a", "b", "x", "y"},
+		`{"missing":{"var":"keys"}}`:                    []interface{}{"b"},
+		`{"missing_some":[2, {"var":"keys"}]}`:          []interface{}{"b"},
+	}
+
+	for rule, target := range rules {
+		result, err := ApplyValue(rule, data)
+		if err != nil || !reflect.DeepEqual(result, target) {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, target, result, err)
+		}
+	}
+}
+
+func TestCustomOperationTypedSlice(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("ints", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return []int{1, 2}, nil
+	})
+
+	rules := map[string]interface{}{
+		`{"in":[2, {"ints":[]}]}`: true,
+		`{"merge":[{"ints":[]}]}`: []interface{}{1, 2},
+		`{"missing":{"ints":[]}}`: []interface{}{1, 2},
+	}
+
+	for rule, target := range rules {
+		result, err := engine.Run(rule)
+		if err != nil || !reflect.DeepEqual(result, target) {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, target, result, err)
+		}
+	}
+
+	program, _ := engine.Compile(`{"ints":[]}`)
+	if _, err := program.EvaluateContext(context.Background(), ``, WithMaxArraySize(1)); err == nil {
+		t.Fatalf("a typed slice larger than the limit should fail to evaluate")
+	}
+}
+
+func FuzzApplyValue(f *testing.F) {
+	seeds := []string{
+		`{"in":[1, {"var":"ints"}]}`,
+		`{"merge":[{"var":"keys"}, {"var":"order.lines"}]}`,
+		`{"missing":{"var":"keys"}}`,
+		`{"missing_some":[1, {"var":"letters"}]}`,
+		`{"map":[{"var":"order.lines"}, {"var":"qty"}]}`,
+		`{"reduce":[{"var":"ints"}, {"+":[{"var":"current"}, {"var":"accumulator"}]}, 0]}`,
+		`{"all":[{"var":"letters"}, {"in":[{"var":""}, {"var":"keys"}]}]}`,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	// Data built in Go, with typed slices, arrays, pointers and structs which json data never has
+	data := map[string]interface{}{
+		"ints":    []int{1, 2, 3},
+		"keys":    []string{"a", "ints"},
+		"letters": [2]string{"x", "y"},
+		"nested":  [][]int{{1}, {2, 3}},
+		"order": &testOrder{
+			ID:    "A1",
+			Lines: []testLine{{SKU: "a", Quantity: 2}},
+			Flags: map[string]bool{"vip": true},
+		},
+		"none": (*testOrder)(nil),
+	}
+
+	f.Fuzz(func(t *testing.T, rule string) {
+		ApplyValue(rule, data)
+		ApplyValue(rule, data["ints"])
+		ApplyValue(rule, data["order"])
+	})
+}
+
+func BenchmarkApplyValue(b *testing.B) {
+	program, _ := Compile(`{ "and" : [
+		{"<" : [ { "var" : "temp" }, 110 ]},
+		{"==" : [ { "var" : "pie.filling" }, "apple" ] }
+	] }`)
+	data := map[string]interface{}{
+		"temp": 100,
+		"pie":  map[string]interface{}{"filling": "apple"},
+	}
+
+	for i := 0; i < b.N; i++ {
+		program.EvaluateValue(data)
+	}
+}
@@ -0,0 +1,253 @@
+package jsonlogic
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dependencies returns the paths of the data a rule reads using the operators of the default engine, see Program.Dependencies.
+func Dependencies(rule string) ([]string, error) {
+	return DefaultEngine.Dependencies(rule)
+}
+
+// Dependencies parses the rule and returns the paths of the data it reads using the operators of the engine,
+// see Program.Dependencies.
+func (e *Engine) Dependencies(rule string) ([]string, error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return program.Dependencies()
+}
+
+// Dependencies returns the sorted paths of the data the rule reads, in the dot notation of 'var', from every 'var'
+// along with the keys of 'missing' and 'missing_some', whether or not they would be reached when evaluated.
+// Within 'map', 'filter' and the other array operations a 'var' reads each element of the array, so its path is
+// that of the array followed by '*', such as 'items.*.price' for {"map":[{"var":"items"}, {"var":"price"}]}.
+// When the data read can't be known without evaluating the rule, such as a 'var' whose key is computed by another
+// operator, the paths which could be found are returned along with a *DependencyError for the first which couldn't.
+func (p *Program) Dependencies() ([]string, error) {
+	d := &dependencies{engine: p.engine, paths: make(map[string]bool)}
+	d.node(p.root, nil)
+
+	paths := make([]string, 0, len(d.paths))
+	for path := range d.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if d.err != nil {
+		return paths, d.err
+	}
+	return paths, nil
+}
+
+// dependencies walks a compiled rule collecting the paths of the data it reads.
+type dependencies struct {
+	engine *Engine
+	paths  map[string]bool
+	err    error
+}
+
+// scope is the data seen by the rules of an array operation, each element of the array. The top level data has no scope.
+type scope struct {
+	// prefix is the path of the elements, such as 'items.*', when they are within the data and known
+	prefix string
+	data   bool
+	known  bool
+	// reduce is true within 'reduce', where the element is 'current' alongside the 'accumulator'
+	reduce bool
+}
+
+// flag records a part of the rule whose data can't be known, keeping only the first.
+func (d *dependencies) flag(n *operatorNode, reason string) {
+	if d.err == nil {
+		d.err = &DependencyError{Operator: n.key, Path: n.path(), Reason: reason}
+	}
+}
+
+func (d *dependencies) node(n node, sc *scope) {
+	switch n := n.(type) {
+	case *operatorNode:
+		d.operator(n, sc)
+	case *arrayNode:
+		for _, item := range n.items {
+			d.node(item, sc)
+		}
+	}
+}
+
+// nodes walks each of the nodes within the same scope.
+func (d *dependencies) nodes(nodes []node, sc *scope) {
+	for _, n := range nodes {
+		d.node(n, sc)
+	}
+}
+
+func (d *dependencies) operator(n *operatorNode, sc *scope) {
+	if _, ok := d.engine.operator(n.key); ok {
+		d.flag(n, "custom operators registered with AddOperator are given all of the data")
+		return
+	}
+	_, custom := d.engine.operation(n.key)
+	arity, ok := operatorArity[n.key]
+	if custom || !ok || len(n.args) < arity.min || (arity.max >= 0 && len(n.args) > arity.max) {
+		d.nodes(n.args, sc)
+		return
+	}
+
+	switch n.key {
+	case "var":
+		d.variable(n, sc)
+	case "missing":
+		if keys, ok := missingKeyArgs(n.args); ok {
+			d.addKeys(n, keys, sc)
+			return
+		}
+		d.flag(n, "the keys are computed")
+		d.nodes(n.args, sc)
+	case "missing_some":
+		d.node(n.args[0], sc)
+		if keys, ok := literal(n.args[1]); ok {
+			if keys, ok := keys.([]interface{}); ok {
+				d.addKeys(n, keys, sc)
+				return
+			}
+		}
+		d.flag(n, "the keys are computed")
+		d.node(n.args[1], sc)
+	case "all", "some", "none", "map", "filter", "reduce":
+		d.node(n.args[0], sc)
+		d.node(n.args[1], d.scopeOf(n.args[0], sc, n.key == "reduce"))
+		d.nodes(n.args[2:], sc)
+	default:
+		d.nodes(n.args, sc)
+	}
+}
+
+// variable adds the path of a 'var', the key of which must be a literal rather than computed.
+func (d *dependencies) variable(n *operatorNode, sc *scope) {
+	if len(n.args) == 0 {
+		d.add(n, "", sc)
+		return
+	}
+
+	if key, ok := n.args[0].(*literalNode); ok {
+		d.add(n, varPath(key.value), sc)
+	} else {
+		d.flag(n, "the key is computed")
+		d.node(n.args[0], sc)
+	}
+	d.nodes(n.args[1:], sc)
+}
+
+// addKeys adds the paths of the keys of 'missing' or 'missing_some'.
+func (d *dependencies) addKeys(n *operatorNode, keys []interface{}, sc *scope) {
+	for _, key := range keys {
+		d.add(n, varPath(key), sc)
+	}
+}
+
+// add adds the path of the data read by a key within the scope.
+func (d *dependencies) add(n *operatorNode, key string, sc *scope) {
+	path, data, known := resolvePath(key, sc)
+	switch {
+	case !data:
+	case !known:
+		d.flag(n, "the elements are of an array which isn't read from the data by a 'var'")
+	case path == "":
+		d.flag(n, "all of the data is read")
+	default:
+		d.paths[path] = true
+	}
+}
+
+// scopeOf returns the scope of the rules run against each element of an array.
+func (d *dependencies) scopeOf(array node, sc *scope, reduce bool) *scope {
+	switch n := array.(type) {
+	case *operatorNode:
+		_, custom := d.engine.operation(n.key)
+		if !custom && n.key == "var" && len(n.args) > 0 && (len(n.args) == 1 || isLiteralNode(n.args[1])) {
+			if key, ok := n.args[0].(*literalNode); ok {
+				path, data, known := resolvePath(varPath(key.value), sc)
+				prefix := "*"
+				if path != "" {
+					prefix = path + ".*"
+				}
+				return &scope{prefix: prefix, data: data, known: known, reduce: reduce}
+			}
+		}
+		// Filtering keeps the elements of the array it filters
+		if !custom && n.key == "filter" && len(n.args) == 2 {
+			return d.scopeOf(n.args[0], sc, reduce)
+		}
+	default:
+		if _, ok := literal(array); ok {
+			return &scope{reduce: reduce}
+		}
+	}
+	return &scope{data: true, reduce: reduce}
+}
+
+// isLiteralNode reports whether a node doesn't depend on the data.
+func isLiteralNode(n node) bool {
+	_, ok := literal(n)
+	return ok
+}
+
+// resolvePath returns the path within the data of a key read within a scope, whether it reads the data at all,
+// as literal arrays and the accumulator of 'reduce' don't, and whether the path is known.
+func resolvePath(key string, sc *scope) (path string, data bool, known bool) {
+	if sc == nil {
+		return key, true, true
+	}
+
+	if sc.reduce {
+		switch {
+		case key == "current":
+			key = ""
+		case strings.HasPrefix(key, "current."):
+			key = strings.TrimPrefix(key, "current.")
+		default:
+			return "", false, true
+		}
+	}
+
+	switch {
+	case !sc.data:
+		return "", false, true
+	case !sc.known:
+		return "", true, false
+	case key == "":
+		return sc.prefix, true, true
+	}
+	return sc.prefix + "." + key, true, true
+}
+
+// varPath returns a var key, either a dot notation string or a numeric index, as a path.
+func varPath(key interface{}) string {
+	if key == nil {
+		return ""
+	}
+	return strings.Join(keySegments(key), ".")
+}
+
+// missingKeyArgs returns the keys of 'missing' when they are literals, either as arguments or a single array.
+func missingKeyArgs(args []node) ([]interface{}, bool) {
+	keys := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		value, ok := literal(arg)
+		if !ok {
+			return nil, false
+		}
+		keys = append(keys, value)
+	}
+
+	if len(keys) > 0 {
+		if array, ok := keys[0].([]interface{}); ok {
+			return array, true
+		}
+	}
+	return keys, true
+}
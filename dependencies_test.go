@@ -0,0 +1,75 @@
+package jsonlogic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDependencies(t *testing.T) {
+	rules := map[string][]string{
+		`{"and":[{"<":[{"var":"temp"}, 110]}, {"==":[{"var":"pie.filling"}, "apple"]}]}`: {"pie.filling", "temp"},
+		`{"if":[{"var":"a"}, {"var":["b", {"var":"c"}]}, {"var":1}]}`:                    {"1", "a", "b", "c"},
+		`{"missing":["a", "b.c"]}`:                                                                             {"a", "b.c"},
+		`{"missing":[["a", "b"]]}`:                                                                             {"a", "b"},
+		`{"missing_some":[{"var":"need"}, ["a", "b"]]}`:                                                        {"a", "b", "need"},
+		`{"map":[{"var":"items"}, {"*":[{"var":"price"}, {"var":"qty"}]}]}`:                                    {"items", "items.*.price", "items.*.qty"},
+		`{"all":[{"filter":[{"var":"items"}, {"var":"active"}]}, {">":[{"var":""}, 0]}]}`:                      {"items", "items.*", "items.*.active"},
+		`{"some":[{"var":"orders"}, {"in":["x", {"map":[{"var":"lines"}, {"var":"sku"}]}]}]}`:                  {"orders", "orders.*.lines", "orders.*.lines.*.sku"},
+		`{"reduce":[{"var":"items"}, {"+":[{"var":"accumulator"}, {"var":"current.price"}]}, {"var":"base"}]}`: {"base", "items", "items.*.price"},
+		`{"map":[[1, 2], {"*":[{"var":""}, 2]}]}`:                                                              {},
+		`[{"var":"a"}, {"cat":["x", {"var":"a"}]}]`:                                                            {"a"},
+		`true`: {},
+	}
+
+	for rule, expected := range rules {
+		paths, err := Dependencies(rule)
+		if err != nil || !reflect.DeepEqual(paths, expected) {
+			t.Fatalf("rule %s should depend on %v, instead returned %v, %v", rule, expected, paths, err)
+		}
+	}
+}
+
+func TestDependenciesDynamic(t *testing.T) {
+	rules := map[string]struct {
+		paths []string
+		path  string
+	}{
+		`{"and":[{"var":"a"}, {"var":{"cat":["b.", {"var":"c"}]}}]}`: {[]string{"a", "c"}, "and[1].var"},
+		`{"missing":{"merge":[["a"], {"var":"keys"}]}}`:              {[]string{"keys"}, "missing"},
+		`{"var":""}`: {[]string{}, "var"},
+		`{"map":[{"merge":[{"var":"a"}, {"var":"b"}]}, {"var":"price"}]}`: {[]string{"a", "b"}, "map[1].var"},
+	}
+
+	for rule, expected := range rules {
+		paths, err := Dependencies(rule)
+
+		var dependency *DependencyError
+		if !errors.As(err, &dependency) || dependency.Path != expected.path {
+			t.Fatalf("rule %s should return a dependency error at %s, instead returned %v", rule, expected.path, err)
+		}
+		if !reflect.DeepEqual(paths, expected.paths) {
+			t.Fatalf("rule %s should depend on %v, instead returned %v", rule, expected.paths, paths)
+		}
+	}
+}
+
+func TestDependenciesCustomOperators(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("double", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return args[0].(float64) * 2, nil
+	})
+	engine.AddOperator("legacy", func(rule string, data string) interface{} {
+		return true
+	})
+
+	paths, err := engine.Dependencies(`{"double":{"var":"a"}}`)
+	if err != nil || !reflect.DeepEqual(paths, []string{"a"}) {
+		t.Fatalf("rule should depend on a, instead returned %v, %v", paths, err)
+	}
+
+	var dependency *DependencyError
+	if _, err := engine.Dependencies(`{"legacy":{"var":"a"}}`); !errors.As(err, &dependency) {
+		t.Fatalf("rule should return a dependency error, instead returned %v", err)
+	}
+}
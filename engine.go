@@ -0,0 +1,179 @@
+package jsonlogic
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// DefaultEngine is the engine used by the package level functions such as Apply and AddOperator.
+// Its custom operators are held in Operators.
+var DefaultEngine = &Engine{operators: Operators, operations: make(map[string]LazyOperation)}
+
+// Engine evaluates rules using its own set of custom operators, so separate parts of a program can each have their own.
+// An Engine is safe for concurrent use, operators can be added or removed while rules are being evaluated.
+type Engine struct {
+	mu         sync.RWMutex
+	operators  map[string]func(rule string, data string) (result interface{})
+	operations map[string]LazyOperation
+}
+
+// NewEngine returns an engine with only the built in operators.
+func NewEngine() *Engine {
+	return &Engine{
+		operators:  make(map[string]func(rule string, data string) (result interface{})),
+		operations: make(map[string]LazyOperation),
+	}
+}
+
+// Clone returns a new engine with a copy of the custom operators, which can then be extended without affecting the original.
+func (e *Engine) Clone() *Engine {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	clone := NewEngine()
+	for key, cb := range e.operators {
+		clone.operators[key] = cb
+	}
+	for key, operation := range e.operations {
+		clone.operations[key] = operation
+	}
+	return clone
+}
+
+// AddOperator registers a custom operator with the engine, replacing any built in or custom operator with the same key.
+// The operator receives the raw json of its arguments and the data, see AddOperation for operators which receive evaluated arguments.
+func (e *Engine) AddOperator(key string, cb func(rule string, data string) (result interface{})) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.operations, key)
+	e.operators[key] = cb
+}
+
+// AddOperation registers a custom operator with the engine which receives its arguments already evaluated,
+// replacing any built in or custom operator with the same key.
+func (e *Engine) AddOperation(key string, operation Operation) {
+	e.AddLazyOperation(key, eager(operation))
+}
+
+// AddLazyOperation registers a custom operator with the engine which evaluates its own arguments,
+// replacing any built in or custom operator with the same key.
+func (e *Engine) AddLazyOperation(key string, operation LazyOperation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.operators, key)
+	e.operations[key] = operation
+}
+
+// RemoveOperator removes a custom operator from the engine, restoring the built in operator if there is one.
+func (e *Engine) RemoveOperator(key string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.operators, key)
+	delete(e.operations, key)
+}
+
+// Operators returns the sorted keys of the custom operators registered with the engine.
+func (e *Engine) Operators() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	keys := make([]string, 0, len(e.operators)+len(e.operations))
+	for key := range e.operators {
+		keys = append(keys, key)
+	}
+	for key := range e.operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Run is an alias to Apply without data
+func (e *Engine) Run(rule string) (res interface{}, errs error) {
+	return e.Apply(rule, ``)
+}
+
+// Apply parses the rule and evaluates it against optional data using the operators of the engine.
+func (e *Engine) Apply(rule string, data string) (res interface{}, errs error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := program.Evaluate(data)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+// ApplyContext parses the rule and evaluates it against optional data using the operators of the engine,
+// stopping with the error of ctx once it is done, whether parsing or evaluating. Options limit the resources the
+// evaluation may use, and a rule nested deeper than WithMaxDepth allows is rejected while it is parsed.
+func (e *Engine) ApplyContext(ctx context.Context, rule string, data string, options ...Option) (res interface{}, errs error) {
+	program, err := e.compile(ctx, rule, newLimits(options))
+	if err != nil {
+		return false, err
+	}
+
+	result, err := program.EvaluateContext(ctx, data, options...)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+// ApplyValue parses the rule and evaluates it against data which has already been decoded or built in Go
+// using the operators of the engine. See Program.EvaluateValue.
+func (e *Engine) ApplyValue(rule string, data interface{}) (res interface{}, errs error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := program.EvaluateValue(data)
+	if err != nil {
+		return false, err
+	}
+
+	return result, nil
+}
+
+// operator looks up a custom operator registered with AddOperator.
+func (e *Engine) operator(key string) (func(rule string, data string) (result interface{}), bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	cb, ok := e.operators[key]
+	return cb, ok
+}
+
+// replaces reports whether any of the keys have a custom operator, replacing a built in operator.
+func (e *Engine) replaces(keys []string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, key := range keys {
+		_, operator := e.operators[key]
+		_, operation := e.operations[key]
+		if operator || operation {
+			return true
+		}
+	}
+	return false
+}
+
+// operation looks up a custom operator registered with AddOperation or AddLazyOperation.
+func (e *Engine) operation(key string) (LazyOperation, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	operation, ok := e.operations[key]
+	return operation, ok
+}
@@ -0,0 +1,149 @@
+package jsonlogic
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cast"
+)
+
+func TestEngineOperators(t *testing.T) {
+	first := NewEngine()
+	first.AddOperator("greet", func(rule string, data string) interface{} {
+		return "hello"
+	})
+	second := NewEngine()
+	second.AddOperator("greet", func(rule string, data string) interface{} {
+		return "bonjour"
+	})
+
+	result, _ := first.Run(`{"greet":[]}`)
+	if result != "hello" {
+		t.Fatalf("rule should return hello, instead returned %v", result)
+	}
+
+	result, _ = second.Run(`{"greet":[]}`)
+	if result != "bonjour" {
+		t.Fatalf("rule should return bonjour, instead returned %v", result)
+	}
+
+	_, err := Run(`{"greet":[]}`)
+	if _, ok := err.(*UnknownOperatorError); !ok {
+		t.Fatalf("rule should return an unknown operator error on the default engine, instead returned %v", err)
+	}
+}
+
+func TestEngineClone(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("one", func(rule string, data string) interface{} {
+		return 1
+	})
+
+	clone := engine.Clone()
+	clone.AddOperator("two", func(rule string, data string) interface{} {
+		return 2
+	})
+
+	if !reflect.DeepEqual(engine.Operators(), []string{"one"}) {
+		t.Fatalf("engine should only have one, instead has %v", engine.Operators())
+	}
+
+	if !reflect.DeepEqual(clone.Operators(), []string{"one", "two"}) {
+		t.Fatalf("clone should have one and two, instead has %v", clone.Operators())
+	}
+
+	result, _ := clone.Run(`{"+":[{"one":[]}, {"two":[]}]}`)
+	if cast.ToInt(result) != 3 {
+		t.Fatalf("rule should return 3, instead returned %v", result)
+	}
+}
+
+func TestEngineOverrideBuiltIn(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("cat", func(rule string, data string) interface{} {
+		return "custom"
+	})
+
+	result, _ := engine.Run(`{"cat":["a", "b"]}`)
+	if result != "custom" {
+		t.Fatalf("rule should return custom, instead returned %v", result)
+	}
+
+	engine.RemoveOperator("cat")
+
+	result, _ = engine.Run(`{"cat":["a", "b"]}`)
+	if result != "ab" {
+		t.Fatalf("rule should return ab, instead returned %v", result)
+	}
+}
+
+func TestEngineProgram(t *testing.T) {
+	engine := NewEngine()
+	program, err := engine.Compile(`{"double":{"var":"a"}}`)
+	if err != nil {
+		t.Fatalf("rule should compile, instead returned %s", err)
+	}
+
+	// Operators are looked up as the program is evaluated, not when it is compiled
+	engine.AddOperator("double", func(rule string, data string) interface{} {
+		return cast.ToFloat64(GetValues(rule, data)[0]) * 2
+	})
+
+	result, _ := program.Evaluate(`{"a":4}`)
+	if cast.ToInt(result) != 8 {
+		t.Fatalf("rule should return 8, instead returned %v", result)
+	}
+}
+
+func TestEngineConcurrent(t *testing.T) {
+	engine := NewEngine()
+	program, _ := engine.Compile(`{"if":[{"custom":[]}, "custom", "none"]}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			engine.AddOperator("custom", func(rule string, data string) interface{} {
+				return true
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			program.Evaluate(``)
+		}()
+	}
+	wg.Wait()
+
+	result, _ := program.Evaluate(``)
+	if result != "custom" {
+		t.Fatalf("rule should return custom, instead returned %v", result)
+	}
+}
+
+func TestDefaultEngine(t *testing.T) {
+	AddOperator("default_only", func(rule string, data string) interface{} {
+		return true
+	})
+	defer DefaultEngine.RemoveOperator("default_only")
+
+	if _, ok := Operators["default_only"]; !ok {
+		t.Fatal("AddOperator should register with Operators")
+	}
+
+	clone := DefaultEngine.Clone()
+	result, _ := clone.Run(`{"default_only":[]}`)
+	if result != true {
+		t.Fatalf("clone should have default_only, instead returned %v", result)
+	}
+}
+
+func TestBuiltInOperators(t *testing.T) {
+	operators := BuiltInOperators()
+
+	if !sort.StringsAreSorted(operators) || len(operators) != len(operatorArity) {
+		t.Fatalf("built in operators should be sorted, instead returned %v", operators)
+	}
+}
@@ -0,0 +1,183 @@
+package jsonlogic
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Paths identify a node within a rule by the operators and argument indexes leading to it, so in
+// {"and":[true, {"<":[{"var":"a"}, 1]}]} the path of the '<' operator is 'and[1].<' and of its first argument 'and[1].<[0]'.
+// The root of the rule has an empty path.
+
+// ErrBudgetExceeded is matched by the *BudgetError returned when an evaluation goes over one of its limits.
+var ErrBudgetExceeded = errors.New("evaluation budget exceeded")
+
+// errInvalidData is the cause of the ParseError returned when data isn't valid json.
+var errInvalidData = errors.New("data is not valid json")
+
+// ParseError is returned when a rule or its data isn't valid json. Path is empty for data.
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf(ErrInvalidOperation, e.Err)
+	}
+	return fmt.Sprintf(ErrInvalidOperation, fmt.Sprintf("%s at %s", e.Err, e.Path))
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownOperatorError is returned when a rule uses an operator which is neither built in nor registered with the engine.
+type UnknownOperatorError struct {
+	Operator string
+	Path     string
+}
+
+func (e *UnknownOperatorError) Error() string {
+	return fmt.Sprintf("unknown operator %q at %s", e.Operator, e.Path)
+}
+
+// ArityError is returned when an operator is given too few or too many arguments.
+// Max is -1 when the operator takes any number of arguments from Min.
+type ArityError struct {
+	Operator string
+	Path     string
+	Min      int
+	Max      int
+	Got      int
+}
+
+func (e *ArityError) Error() string {
+	return fmt.Sprintf("operator %q at %s expects %s arguments, got %d", e.Operator, e.Path, e.expects(), e.Got)
+}
+
+// expects describes the number of arguments the operator accepts, such as 'at least 1'.
+func (e *ArityError) expects() string {
+	switch {
+	case e.Min == e.Max:
+		return fmt.Sprintf("%d", e.Min)
+	case e.Max < 0:
+		return fmt.Sprintf("at least %d", e.Min)
+	}
+	return fmt.Sprintf("%d to %d", e.Min, e.Max)
+}
+
+// TypeError is returned by custom operations given a value they can't work with, and reported by Validate for a
+// literal argument of the wrong type. Built in operators coerce their arguments as JavaScript does instead.
+// Path is the path of the offending argument when reported by Validate, otherwise that of the operator.
+type TypeError struct {
+	Operator string
+	Path     string
+	Expected string
+	Value    interface{}
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("operator %q at %s expects %s, got %#v", e.Operator, e.Path, e.Expected, e.Value)
+}
+
+// BudgetError is returned when an evaluation goes over one of the limits it was given, such as WithMaxDepth.
+// Path is the path of the operator which went over the limit.
+type BudgetError struct {
+	Limit string
+	Max   int
+	Path  string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %d exceeded at %s", ErrBudgetExceeded, e.Limit, e.Max, e.Path)
+}
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// ConversionError is returned by the typed Apply functions, such as ApplyFloat, when the result of a rule
+// can't be converted to the type asked for.
+type ConversionError struct {
+	Value interface{}
+	Type  string
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("cannot convert %#v to %s: %s", e.Value, e.Type, e.Err)
+	}
+	return fmt.Sprintf("cannot convert %#v to %s", e.Value, e.Type)
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// DependencyError is returned by Dependencies when the data read by an operator can't be known without evaluating
+// the rule, such as a 'var' whose key is computed. Path is the path of the operator.
+type DependencyError struct {
+	Operator string
+	Path     string
+	Reason   string
+}
+
+func (e *DependencyError) Error() string {
+	return fmt.Sprintf("data read by operator %q at %s can't be known: %s", e.Operator, e.Path, e.Reason)
+}
+
+// TranslationError is returned when a rule can't be translated into another query language, such as SQL by ToSQL,
+// as it uses an operator or value with no translation. Path is the path of the operator or value.
+type TranslationError struct {
+	Target   string
+	Operator string
+	Path     string
+	Reason   string
+	Err      error
+}
+
+func (e *TranslationError) Error() string {
+	if e.Operator == "" {
+		return fmt.Sprintf("value at %s can't be translated to %s: %s", e.Path, e.Target, e.Reason)
+	}
+	return fmt.Sprintf("operator %q at %s can't be translated to %s: %s", e.Operator, e.Path, e.Target, e.Reason)
+}
+
+func (e *TranslationError) Unwrap() error {
+	return e.Err
+}
+
+// withPath fills in the operator and path of errors returned without them, such as those from custom operators.
+func withPath(err error, key string, at *location) error {
+	if err == nil {
+		return nil
+	}
+
+	path := at.String()
+	var unknown *UnknownOperatorError
+	var arity *ArityError
+	var typeErr *TypeError
+	switch {
+	case errors.As(err, &unknown):
+		if unknown.Path == "" {
+			unknown.Path = path
+		}
+	case errors.As(err, &arity):
+		if arity.Operator == "" {
+			arity.Operator = key
+		}
+		if arity.Path == "" {
+			arity.Path = path
+		}
+	case errors.As(err, &typeErr):
+		if typeErr.Operator == "" {
+			typeErr.Operator = key
+		}
+		if typeErr.Path == "" {
+			typeErr.Path = path
+		}
+	}
+	return err
+}
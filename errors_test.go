@@ -0,0 +1,107 @@
+package jsonlogic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrorPath(t *testing.T) {
+	_, err := Run(`{"and":[true, {"<":[1, "\x"]}]}`)
+
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("rule should return a parse error, instead returned %v", err)
+	}
+
+	if parseErr.Path != "and[1].<[1]" {
+		t.Fatalf("error should be at and[1].<[1], instead at %s", parseErr.Path)
+	}
+}
+
+func TestParseErrorRoot(t *testing.T) {
+	_, err := Run(``)
+
+	parseErr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("rule should return a parse error, instead returned %v", err)
+	}
+
+	if parseErr.Path != "" {
+		t.Fatalf("error should be at the root, instead at %s", parseErr.Path)
+	}
+}
+
+func TestUnknownOperatorError(t *testing.T) {
+	_, err := Run(`{"and":[true, {"<":[{"nope":[]}, 1]}]}`)
+
+	unknown, ok := err.(*UnknownOperatorError)
+	if !ok {
+		t.Fatalf("rule should return an unknown operator error, instead returned %v", err)
+	}
+
+	if unknown.Operator != "nope" || unknown.Path != "and[1].<[0].nope" {
+		t.Fatalf("error should be for nope at and[1].<[0].nope, instead for %s at %s", unknown.Operator, unknown.Path)
+	}
+
+	if err.Error() != `unknown operator "nope" at and[1].<[0].nope` {
+		t.Fatalf("unexpected error message %s", err)
+	}
+}
+
+func TestUnknownOperatorSkipped(t *testing.T) {
+	result, err := Run(`{"or":[true, {"nope":[]}]}`)
+
+	if err != nil || result != true {
+		t.Fatalf("rule should return true without evaluating nope, instead returned %v, %v", result, err)
+	}
+}
+
+func TestCustomOperationErrorPath(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("pair", func(ctx *Context, args []interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, &ArityError{Min: 2, Max: 2, Got: len(args)}
+		}
+		return args, nil
+	})
+	engine.AddOperation("string", func(ctx *Context, args []interface{}) (interface{}, error) {
+		if _, ok := args[0].(string); !ok {
+			return nil, &TypeError{Expected: "a string", Value: args[0]}
+		}
+		return args[0], nil
+	})
+
+	_, err := engine.Run(`{"if":[true, {"pair":[1]}]}`)
+
+	var arity *ArityError
+	if !errors.As(err, &arity) {
+		t.Fatalf("rule should return an arity error, instead returned %v", err)
+	}
+
+	if arity.Operator != "pair" || arity.Path != "if[1].pair" {
+		t.Fatalf("error should be for pair at if[1].pair, instead for %s at %s", arity.Operator, arity.Path)
+	}
+
+	if err.Error() != `operator "pair" at if[1].pair expects 2 arguments, got 1` {
+		t.Fatalf("unexpected error message %s", err)
+	}
+
+	_, err = engine.Run(`{"map":[[1], {"string":{"var":""}}]}`)
+
+	var typeErr *TypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("rule should return a type error, instead returned %v", err)
+	}
+
+	if typeErr.Path != "map[1].string" {
+		t.Fatalf("error should be at map[1].string, instead at %s", typeErr.Path)
+	}
+}
+
+func TestParseErrorData(t *testing.T) {
+	_, err := Apply(`{"var":"."}`, `[`)
+
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("invalid data should return a parse error, instead returned %v", err)
+	}
+}
@@ -1,6 +1,6 @@
 module github.com/GeorgeD19/json-logic-go
 
-go 1.17
+go 1.18
 
 require (
 	github.com/buger/jsonparser v0.0.0-20191004114745-ee4c978eae7e
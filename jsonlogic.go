@@ -2,9 +2,12 @@ package jsonlogic
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -19,179 +22,221 @@ var (
 	ErrInvalidOperation = "invalid operation: %s"
 )
 
-// Operators holds any operators
+// Operators holds the custom operators of the default engine.
+//
+// Deprecated: Use AddOperator, which is safe to call while rules are being evaluated, or an Engine.
+// Operators must not be modified directly once rules are being evaluated.
 var Operators = make(map[string]func(rule string, data string) (result interface{}))
 
 // Run is an alias to Apply without data
 func Run(rule string) (res interface{}, errs error) {
-	return Apply(rule, ``)
+	return DefaultEngine.Run(rule)
 }
 
 // Apply is the entry function to parse rule and optional data
 func Apply(rule string, data string) (res interface{}, errs error) {
+	return DefaultEngine.Apply(rule, data)
+}
 
-	// Ensure data is object
-	if data == `` {
-		data = `{}`
-	}
-
-	// Unicode &
-	data = strings.ReplaceAll(data, `\u0026`, `&`)
-
-	// Must be an object to start process
-	result, err := ParseOperator(rule, data)
-	if err != nil {
-		return false, err
-	}
+// ApplyContext is Apply which stops with the error of ctx once it is done, such as when its deadline passes.
+// Options limit the resources the evaluation may use, see WithMaxDepth, WithMaxOperations and WithMaxArraySize.
+func ApplyContext(ctx context.Context, rule string, data string, options ...Option) (res interface{}, errs error) {
+	return DefaultEngine.ApplyContext(ctx, rule, data, options...)
+}
 
-	return result, nil
+// ApplyValue parses rule and evaluates it against data which has already been decoded or built in Go, such as
+// map[string]interface{}, slices and structs, without serializing it to json.
+func ApplyValue(rule string, data interface{}) (res interface{}, errs error) {
+	return DefaultEngine.ApplyValue(rule, data)
 }
 
 // ParseOperator takes in the json rule and data and attempts to parse
 func ParseOperator(rule string, data string) (result interface{}, err error) {
-	err = jsonparser.ObjectEach([]byte(rule), func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
-		switch dataType {
-		case jsonparser.String:
-			result = RunOperator(string(key), "\""+string(value)+"\"", data)
-		default:
-			result = RunOperator(string(key), string(value), data)
-		}
-		return nil
-	})
-
+	program, err := Compile(rule)
 	if err != nil {
-		return false, fmt.Errorf(ErrInvalidOperation, err)
+		return false, err
 	}
 
-	return result, nil
+	if !json.Valid([]byte(data)) {
+		return false, &ParseError{Err: errInvalidData}
+	}
+
+	return program.root.eval(newEvaluation(context.Background(), program.engine, nil), jsonData(data))
 }
 
 // GetValues will attempt to recursively resolve all values for a given operator
 func GetValues(rule string, data string) (results []interface{}) {
-
-	ruleValue, dataType, _, _ := jsonparser.Get([]byte(rule))
-	switch dataType {
-	case jsonparser.Object:
-		res, _ := ParseOperator(string(ruleValue), data)
-		results = append(results, res)
-	case jsonparser.Array:
-		jsonparser.ArrayEach([]byte(ruleValue), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
-			switch dataType {
-			case jsonparser.Array:
-				m := make([]interface{}, 0)
-				json.Unmarshal(value, &m)
-				results = append(results, m)
-			case jsonparser.Object:
-				res, _ := ParseOperator(string(value), data)
-				results = append(results, res)
-			case jsonparser.String:
-				results = append(results, cast.ToString(value))
-			case jsonparser.Number:
-				results = append(results, cast.ToFloat64(cast.ToString(value)))
-			case jsonparser.Boolean:
-				results = append(results, cast.ToBool(string(value)))
-			case jsonparser.Null:
-				results = append(results, value)
-			}
-		})
-	case jsonparser.Number:
-		results = append(results, cast.ToFloat64(string(ruleValue)))
-	case jsonparser.String:
-		// Remove the quotes we added so we could detect string type
-		rule = rule[1 : len(rule)-1]
-		value, dataType, _, _ := jsonparser.Get([]byte(data), rule)
-		if len(value) > 0 {
-			results = append(results, rule)
-			switch dataType {
-			case jsonparser.String:
-				results = append(results, cast.ToString(value))
-			case jsonparser.Number:
-				results = append(results, cast.ToFloat64(cast.ToString(value)))
-			case jsonparser.Boolean:
-				results = append(results, cast.ToBool(value))
-			case jsonparser.Null:
-				results = append(results, value)
-			}
-		} else {
-			// No data was found so we just append the rule and move on
-			results = append(results, rule)
-		}
-	default:
+	args, err := compileArguments([]byte(rule), nil)
+	if err != nil {
 		return nil
 	}
 
+	results, _ = evalArguments(newEvaluation(context.Background(), DefaultEngine, nil), args, jsonData(data))
 	return results
 }
 
 // AddOperator allows for custom operators to be used
 func AddOperator(key string, cb func(rule string, data string) (result interface{})) {
-	Operators[key] = cb
+	DefaultEngine.AddOperator(key, cb)
 }
 
 // RunOperator determines what function to run against the passed rule and data
 func RunOperator(key string, rule string, data string) (result interface{}) {
+	at := (*location)(nil).operator(key)
+	args, err := compileArguments([]byte(rule), at)
+	if err != nil {
+		return nil
+	}
+
+	node := &operatorNode{key: key, args: args, rule: []byte(rule), at: at}
+	result, _ = node.eval(newEvaluation(context.Background(), DefaultEngine, nil), jsonData(data))
+	return result
+}
+
+// operatorArity holds the number of arguments each built in operator accepts, where a max of -1 is any number.
+var operatorArity = map[string]struct{ min, max int }{
+	// Accessing Data
+	"var":          {0, 2},
+	"missing":      {0, -1},
+	"missing_some": {2, 2},
+	// Logic and Boolean Operations
+	"if":  {0, -1},
+	"?:":  {0, -1},
+	"==":  {2, 2},
+	"===": {2, 2},
+	"!=":  {2, 2},
+	"!==": {2, 2},
+	"!":   {0, 1},
+	"!!":  {0, 1},
+	"or":  {0, -1},
+	"and": {0, -1},
+	// Numeric Operations
+	">":   {2, 2},
+	">=":  {2, 2},
+	"<":   {2, 3},
+	"<=":  {2, 3},
+	"max": {0, -1},
+	"min": {0, -1},
+	"+":   {0, -1},
+	"-":   {1, -1},
+	"*":   {0, -1},
+	"/":   {2, 2},
+	"%":   {2, 2},
+	// Percentage is kept from when it was implemented by '%'
+	"percentage": {2, 2},
+	// String Operations
+	"cat":    {0, -1},
+	"in":     {2, 2},
+	"substr": {2, 3},
+	// Array Operations
+	"merge":  {0, -1},
+	"all":    {2, 2},
+	"some":   {2, 2},
+	"none":   {2, 2},
+	"map":    {2, 2},
+	"reduce": {2, 3},
+	"filter": {2, 2},
+	// Miscellaneous
+	"log": {1, 1},
+}
+
+// BuiltInOperators returns the sorted keys of the operators built in to every engine.
+func BuiltInOperators() []string {
+	keys := make([]string, 0, len(operatorArity))
+	for key := range operatorArity {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runOperator runs a built in operator against its compiled arguments.
+func runOperator(ev *evaluation, key string, args []node, data interface{}) (result interface{}, err error) {
+	arity, ok := operatorArity[key]
+	if !ok {
+		return nil, &UnknownOperatorError{Operator: key}
+	}
+	if len(args) < arity.min || (arity.max >= 0 && len(args) > arity.max) {
+		return nil, &ArityError{Operator: key, Min: arity.min, Max: arity.max, Got: len(args)}
+	}
+
+	// Logic and array operations decide for themselves which arguments to resolve
+	switch key {
+	case "if", "?:":
+		return evalIf(ev, args, data)
+	case "or":
+		return evalOr(ev, args, data)
+	case "and":
+		return evalAnd(ev, args, data)
+		// All, None and Some http://jsonlogic.com/operations.html#all-none-and-some
+	case "all":
+		return evalAll(ev, args, data)
+	case "some":
+		return evalSome(ev, args, data)
+	case "none":
+		return evalNone(ev, args, data)
+		// Map, Reduce and Filter http://jsonlogic.com/operations.html#map-reduce-and-filter
+	case "map":
+		return evalMap(ev, args, data)
+	case "reduce":
+		return evalReduce(ev, args, data)
+	case "filter":
+		return evalFilter(ev, args, data)
+	}
+
+	values, err := evalArguments(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
 
-	values := GetValues(rule, data)
 	switch key {
 	// Accessing Data
 	case "var":
-		var fallback interface{}
+		var key, fallback interface{}
+		if len(values) > 0 {
+			key = values[0]
+		}
 		if len(values) > 1 {
 			fallback = values[1]
-		} else {
-			fallback = nil
 		}
 
-		result = Var(values[0], fallback, data)
-	// TODO missing
+		result = resolveVar(key, fallback, data)
 	case "missing":
-		result = Missing(values, data)
+		result = missingKeys(values, data)
 	case "missing_some":
-
-		break
-	// TODO missing_some
+		keys := toSlice(values[1])
+		result = missingSome(cast.ToInt(values[0]), keys, data)
 	// Logic and Boolean Operations
-	case "?":
-	case "if":
-		// TOFIX basically the "success" value is showing false when it should be showing true
-		// result = If(values[0], values[1], values[2])
-		result = If(values)
 	case "==":
-		result = SoftEqual(cast.ToString(values[0]), cast.ToString(values[1]))
+		result = SoftEqual(values[0], values[1])
 	case "===":
 		result = HardEqual(values[0], values[1])
 	case "!=":
-		result = NotSoftEqual(cast.ToString(values[0]), cast.ToString(values[1]))
+		result = NotSoftEqual(values[0], values[1])
 	case "!==":
 		result = NotHardEqual(values[0], values[1])
 	case "!":
-		result = NotTruthy(values)
+		result = !truthy(first(values))
 	case "!!":
-		result = Truthy(values)
-	case "or":
-		result = Or(values)
-	case "and":
-		result = And(values)
+		result = truthy(first(values))
 		// Numeric Operations
 	case ">":
-		result = More(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]))
+		result = More(values[0], values[1])
 	case ">=":
-		result = MoreEqual(cast.ToString(values[0]), cast.ToString(values[1]))
+		result = MoreEqual(values[0], values[1])
 	case "<":
 		// Test for exclusive between
-		result = false
-		if len(values) > 2 && IsNumeric(values[0]) && IsNumeric(values[1]) && IsNumeric(values[2]) {
-			result = LessBetween(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]), cast.ToFloat64(values[2]))
-		} else if IsNumeric(values[0]) && IsNumeric(values[1]) {
-			result = Less(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]))
+		if len(values) > 2 {
+			result = LessBetween(values[0], values[1], values[2])
+		} else {
+			result = Less(values[0], values[1])
 		}
 	case "<=":
 		// Test for inclusive between
-		result = false
-		if len(values) > 2 && IsNumeric(values[0]) && IsNumeric(values[1]) && IsNumeric(values[2]) {
-			result = LessEqualBetween(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]), cast.ToFloat64(values[2]))
-		} else if IsNumeric(values[0]) && IsNumeric(values[1]) {
-			result = LessEqual(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]))
+		if len(values) > 2 {
+			result = LessEqualBetween(values[0], values[1], values[2])
+		} else {
+			result = LessEqual(values[0], values[1])
 		}
 	case "max":
 		result = Max(values)
@@ -206,6 +251,8 @@ func RunOperator(key string, rule string, data string) (result interface{}) {
 	case "/":
 		result = Divide(cast.ToFloat64(values[0]), cast.ToFloat64(values[1]))
 	case "%":
+		result = Modulo(toNumber(values[0]), toNumber(values[1]))
+	case "percentage":
 		result = Percentage(cast.ToInt(values[0]), cast.ToInt(values[1]))
 		// String Operations
 	case "cat":
@@ -220,39 +267,12 @@ func RunOperator(key string, rule string, data string) (result interface{}) {
 		}
 	case "merge":
 		result = Merge(values)
-		// TODO All, None and Some http://jsonlogic.com/operations.html#all-none-and-some
-	case "all":
-
-		break
-	case "some":
-
-		break
-	case "none":
-
-		break
-		// TODO Map, Reduce and Filter http://jsonlogic.com/operations.html#map-reduce-and-filter
-	case "map":
-
-		break
-	case "reduce":
-
-		break
-	case "filter":
-
-		break
 		// Miscellaneous
 	case "log":
 		result = Log(cast.ToString(values[0]))
 	}
 
-	// Check against any custom operators
-	for index, operation := range Operators {
-		if key == index {
-			result = operation(rule, data)
-		}
-	}
-
-	return result
+	return result, nil
 }
 
 func IsNumeric(s interface{}) bool {
@@ -260,12 +280,36 @@ func IsNumeric(s interface{}) bool {
 	return err == nil
 }
 
+// Missing implements the 'missing' operator returning the keys which are absent, null or empty in data.
+// Keys may use dot notation and can be passed as a single array, such as the result of 'merge'.
 func Missing(a []interface{}, data string) interface{} {
+	return missingKeys(a, jsonData(data))
+}
+
+// MissingSome implements the 'missing_some' operator returning the missing keys unless at least need of them are present.
+func MissingSome(need int, keys []interface{}, data string) interface{} {
+	return missingSome(need, keys, jsonData(data))
+}
+
+func missingSome(need int, keys []interface{}, data interface{}) []interface{} {
+	missing := missingKeys(keys, data)
+	if len(keys)-len(missing) >= need {
+		return make([]interface{}, 0)
+	}
+	return missing
+}
+
+func missingKeys(a []interface{}, data interface{}) []interface{} {
 	result := make([]interface{}, 0)
 
+	if len(a) > 0 {
+		if array, _ := isArray(a[0]); array {
+			a = toSlice(a[0])
+		}
+	}
+
 	for i := 0; i < len(a); i++ {
-		_, dataType, _, _ := jsonparser.Get([]byte(data), cast.ToString(a[i]))
-		if dataType == jsonparser.NotExist {
+		if isMissing(a[i], data) {
 			result = append(result, a[i])
 		}
 	}
@@ -273,13 +317,145 @@ func Missing(a []interface{}, data string) interface{} {
 	return result
 }
 
+// scopedItems resolves the array an array operation works on along with the rule to run against each element.
+func scopedItems(ev *evaluation, args []node, data interface{}) (items []interface{}, scopedRule node, err error) {
+	if len(args) < 2 {
+		return nil, nil, nil
+	}
+
+	value, err := args[0].eval(ev, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toSlice(value), args[1], nil
+}
+
+// evalScoped runs the rule against a single element, which becomes the data seen by 'var'.
+func evalScoped(ev *evaluation, rule node, item interface{}) (interface{}, error) {
+	return rule.eval(ev, item)
+}
+
+// evalAll implements the 'all' operator returning true if the rule is truthy for every element, false for an empty array.
+func evalAll(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	items, scopedRule, err := scopedItems(ev, args, data)
+	if err != nil || len(items) == 0 {
+		return false, err
+	}
+
+	for _, item := range items {
+		result, err := evalScoped(ev, scopedRule, item)
+		if err != nil {
+			return nil, err
+		}
+		if !truthy(result) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// evalSome implements the 'some' operator returning true if the rule is truthy for at least one element.
+func evalSome(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	items, scopedRule, err := scopedItems(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		result, err := evalScoped(ev, scopedRule, item)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(result) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// evalNone implements the 'none' operator returning true if the rule is truthy for none of the elements.
+func evalNone(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	some, err := evalSome(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
+	return !some.(bool), nil
+}
+
+// evalMap implements the 'map' operator returning the result of running the rule against each element.
+func evalMap(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	items, scopedRule, err := scopedItems(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		value, err := evalScoped(ev, scopedRule, item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// evalFilter implements the 'filter' operator returning the elements the rule is truthy for.
+func evalFilter(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	items, scopedRule, err := scopedItems(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+	for _, item := range items {
+		value, err := evalScoped(ev, scopedRule, item)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(value) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// evalReduce implements the 'reduce' operator where the rule sees each element as 'current' and the running result as 'accumulator'.
+func evalReduce(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	var accumulator interface{}
+	if len(args) > 2 {
+		initial, err := args[2].eval(ev, data)
+		if err != nil {
+			return nil, err
+		}
+		accumulator = initial
+	}
+
+	items, scopedRule, err := scopedItems(ev, args, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		accumulator, err = evalScoped(ev, scopedRule, map[string]interface{}{
+			"current":     item,
+			"accumulator": accumulator,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return accumulator, nil
+}
+
 func Merge(a []interface{}) interface{} {
 	result := make([]interface{}, 0)
 
 	for i := 0; i < len(a); i++ {
 		array, _ := isArray(a[i])
 		if array {
-			item := a[i].([]interface{})
+			item := toSlice(a[i])
 			for x := 0; x < len(item); x++ {
 				result = append(result, item[x])
 			}
@@ -291,36 +467,54 @@ func Merge(a []interface{}) interface{} {
 	return result
 }
 
+// Substr implements the 'substr' operator returning part of a string from position, counting from the end if negative.
+// A positive length limits the number of characters, a negative length leaves that many off the end.
+// Positions and lengths outside of the string are clamped to it.
 func Substr(a string, position int, length int) string {
+	chars := []rune(a)
 	start := 0
-	end := len(a)
+	end := len(chars)
 
 	if position < 0 {
 		start = end + position
 	} else {
 		start = position
 	}
+	start = clamp(start, 0, len(chars))
 
 	if length < 0 {
 		end = end + length
 	} else if length > 0 {
-		end = position + length
+		end = start + length
 	}
+	end = clamp(end, start, len(chars))
 
-	return a[start:end]
+	return string(chars[start:end])
 }
 
+// clamp restricts i to between min and max inclusive.
+func clamp(i int, min int, max int) int {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// In implements the 'in' operator, which is whether an array has an element strictly equal to the value, as
+// JavaScript's indexOf, or otherwise whether a string contains it.
 func In(a []interface{}) bool {
 	array, _ := isArray(a[1])
 	if array {
-		items := a[1].([]interface{})
-		result := false
+		items := toSlice(a[1])
 		for i := 0; i < len(items); i++ {
-			if strings.Contains(cast.ToString(items[i]), cast.ToString(a[0])) && a[0] != nil {
-				result = true
+			if strictEqual(items[i], a[0]) {
+				return true
 			}
 		}
-		return result
+		return false
 	}
 	return strings.Contains(cast.ToString(a[1]), cast.ToString(a[0]))
 }
@@ -418,73 +612,56 @@ func Divide(a float64, b float64) interface{} {
 }
 
 // SoftEqual implements the '==' operator, which does type JS-style coertion.
-func SoftEqual(a string, b string) bool {
-	return a == b
+func SoftEqual(a interface{}, b interface{}) bool {
+	return looseEqual(a, b)
 }
 
-// HardEqual Implements the '===' operator, which does type JS-style coertion.
+// HardEqual Implements the '===' operator, which requires values to be of the same type.
 func HardEqual(a ...interface{}) bool {
-	if GetType(a[0]) != GetType(a[1]) {
-		return false
-	}
-
-	if a[0] == a[1] {
-		return true
-	}
-
-	return false
+	return strictEqual(a[0], a[1])
 }
 
 // NotSoftEqual implements the '!=' operator, which does type JS-style coertion.
-func NotSoftEqual(a string, b string) bool {
+func NotSoftEqual(a interface{}, b interface{}) bool {
 	return !SoftEqual(a, b)
 }
 
-// NotHardEqual implements the '!==' operator, which does type JS-style coertion.
+// NotHardEqual implements the '!==' operator, which requires values to be of the same type.
 func NotHardEqual(a ...interface{}) bool {
 	return !HardEqual(a[0], a[1])
 }
 
-// More implements the '>' operator with JS-style type coertion.
-func More(a float64, b float64) bool {
-	return LessEqual(b, a)
+// More implements the '>' operator with JS-style type coertion, which is false for equal values.
+func More(a interface{}, b interface{}) bool {
+	return Less(b, a)
 }
 
 // MoreEqual implements the '>=' operator with JS-style type coertion.
-func MoreEqual(a string, b string) bool {
-	return Less(cast.ToFloat64(b), cast.ToFloat64(a)) || SoftEqual(a, b)
+func MoreEqual(a interface{}, b interface{}) bool {
+	less, ok := lessThan(a, b)
+	return ok && !less
 }
 
 // Less implements the '<' operator however checks against 3 values to test that one value is between but not equal to two others.
-func LessBetween(a float64, b float64, c float64) bool {
-	leftCheck := Less(a, b)
-	rightCheck := Less(b, c)
-
-	if leftCheck && rightCheck {
-		return true
-	}
-	return false
+func LessBetween(a interface{}, b interface{}, c interface{}) bool {
+	return Less(a, b) && Less(b, c)
 }
 
-// Less implements the '<' operator with JS-style type coertion.
-func Less(a float64, b float64) bool {
-	return a < b
+// Less implements the '<' operator with JS-style type coertion, where two strings are compared lexically.
+func Less(a interface{}, b interface{}) bool {
+	less, _ := lessThan(a, b)
+	return less
 }
 
 // Less implements the '<' operator however checks against 3 values to test that one value is between two others.
-func LessEqualBetween(a float64, b float64, c float64) bool {
-	leftCheck := LessEqual(a, b)
-	rightCheck := LessEqual(b, c)
-
-	if leftCheck && rightCheck {
-		return true
-	}
-	return false
+func LessEqualBetween(a interface{}, b interface{}, c interface{}) bool {
+	return LessEqual(a, b) && LessEqual(b, c)
 }
 
 // LessEqual implements the '<=' operator with JS-style type coertion.
-func LessEqual(a float64, b float64) bool {
-	return a <= b
+func LessEqual(a interface{}, b interface{}) bool {
+	less, ok := lessThan(b, a)
+	return ok && !less
 }
 
 // NotTruthy implements the '!' operator with JS-style type coertion.
@@ -502,89 +679,138 @@ func Truthy(a interface{}) bool {
 	return cast.ToBool(a)
 }
 
-// Percentage implements the '%' operator, which does type JS-style coertion. Returns float64.
+// first returns the first of the arguments of an operator, nil if there are none.
+func first(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+// truthy implements JsonLogic truthiness, which follows JavaScript except that an empty array is false.
+func truthy(a interface{}) bool {
+	val := reflect.ValueOf(a)
+	switch val.Kind() {
+	case reflect.Invalid:
+		return false
+	case reflect.Bool:
+		return val.Bool()
+	case reflect.String, reflect.Slice, reflect.Array:
+		return val.Len() > 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int() != 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return val.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		return val.Float() != 0 && !math.IsNaN(val.Float())
+	case reflect.Ptr, reflect.Interface:
+		return !val.IsNil() && truthy(val.Elem().Interface())
+	}
+	return true
+}
+
+// Modulo implements the '%' operator as JavaScript does, so the result takes the sign of a and is NaN when b is 0.
+func Modulo(a float64, b float64) float64 {
+	return math.Mod(a, b)
+}
+
+// Percentage implements the 'percentage' operator, returning a as a percentage of b. Returns float64.
 func Percentage(a int, b int) float64 {
 	return percent.PercentOf(a, b)
 }
 
-// And implements the 'and' conditional requiring all bubbled up bools to be true.
-func And(values []interface{}) bool {
-	result := true
-	for _, res := range values {
-		if res == false {
-			result = false
+// evalAnd implements the 'and' conditional returning the first falsy value, or the last value if all are truthy.
+// Values are resolved in order and resolving stops at the first falsy one.
+func evalAnd(ev *evaluation, args []node, data interface{}) (result interface{}, err error) {
+	for _, arg := range args {
+		result, err = arg.eval(ev, data)
+		if err != nil || !truthy(result) {
+			return result, err
 		}
 	}
-	return result
+	return result, nil
 }
 
-// Or implements the 'or' conditional requiring at least one of the bubbled up bools to be true.
-func Or(values []interface{}) bool {
-	result := false
-	for _, res := range values {
-		if res == true {
-			result = true
+// evalOr implements the 'or' conditional returning the first truthy value, or the last value if none are truthy.
+// Values are resolved in order and resolving stops at the first truthy one.
+func evalOr(ev *evaluation, args []node, data interface{}) (result interface{}, err error) {
+	for _, arg := range args {
+		result, err = arg.eval(ev, data)
+		if err != nil || truthy(result) {
+			return result, err
 		}
 	}
-	return result
+	return result, nil
 }
 
-// If implements the 'if' conditional where if the first value is true, the second value is returned, otherwise the third.
-// func If(conditional interface{}, success interface{}, fail interface{}) interface{} {
-func If(conditions []interface{}) interface{} {
-	var result interface{}
-
-	lastElement := conditions[len(conditions)-1]
-	isTrue := false
+// evalIf implements the 'if' and '?:' conditionals. Arguments are condition and value pairs where the value of the first
+// truthy condition is returned, otherwise the final unpaired value if there is one. Only the branch taken is resolved.
+func evalIf(ev *evaluation, args []node, data interface{}) (interface{}, error) {
+	i := 0
+	for ; i+1 < len(args); i += 2 {
+		condition, err := args[i].eval(ev, data)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(condition) {
+			return args[i+1].eval(ev, data)
+		}
+	}
 
-	for i := 0; i < len(conditions); i++ {
+	if i < len(args) {
+		return args[i].eval(ev, data)
+	}
+	return nil, nil
+}
 
-		if (i + 1) < len(conditions) {
-			value := conditions[i+1]
-			if cast.ToBool(conditions[i]) {
-				result = value
-				isTrue = true
-			}
+// And reports whether all of the values are truthy.
+//
+// Deprecated: 'and' is evaluated lazily by Apply, returning the first falsy value rather than a bool.
+func And(values []interface{}) bool {
+	for _, value := range values {
+		if !truthy(value) {
+			return false
 		}
+	}
+	return true
+}
 
-		i++
+// Or reports whether any of the values are truthy.
+//
+// Deprecated: 'or' is evaluated lazily by Apply, returning the first truthy value rather than a bool.
+func Or(values []interface{}) bool {
+	for _, value := range values {
+		if truthy(value) {
+			return true
+		}
 	}
+	return false
+}
 
-	if isTrue {
-		return result
+// If returns the value following the first truthy condition of condition and value pairs, otherwise the final
+// unpaired value if there is one.
+//
+// Deprecated: 'if' is evaluated lazily by Apply, only resolving the branch taken.
+func If(conditions []interface{}) interface{} {
+	args := make([]node, 0, len(conditions))
+	for _, condition := range conditions {
+		args = append(args, &literalNode{value: condition})
 	}
 
-	return lastElement
+	result, _ := evalIf(nil, args, nil)
+	return result
 }
 
 // Var implements the 'var' operator, which grabs value from passed data and has a fallback.
 func Var(rules interface{}, fallback interface{}, data string) (value interface{}) {
-	ruleType := GetType(rules)
-	rule := ""
-	switch ruleType {
-	case 1:
-	case 2:
-		rule = "[" + cast.ToString(rules) + "]"
-	default:
-		rule = cast.ToString(rules)
-	}
-
 	if cast.ToString(rules) == "" {
 		dataValue, dataType, _, _ := jsonparser.Get([]byte(data))
-		if dataType != jsonparser.NotExist {
-			value = TranslateType(dataValue, dataType)
-		}
-	} else {
-		key := strings.Split(rule, ".")
-		dataValue, dataType, _, _ := jsonparser.Get([]byte(data), key...)
-		value = TranslateType(dataValue, dataType)
-		if value == nil {
-			value = fallback
-		}
+		return TranslateType(dataValue, dataType)
 	}
 
-	if value == "" {
-		value = data
+	value = TranslateType(jsonGet([]byte(data), rules))
+	if value == nil {
+		value = fallback
 	}
 
 	return value
@@ -609,18 +835,33 @@ func GetType(a interface{}) int {
 }
 
 // TranslateType Takes the returned dataType from jsonparser along with it's returned []byte data and returns the casted value.
+// Objects are returned as map[string]interface{} and arrays as []interface{}, the same as encoding/json decodes them.
 func TranslateType(data []byte, dataType jsonparser.ValueType) interface{} {
 	switch dataType {
 	case jsonparser.String:
-		return string(data)
+		str, err := jsonparser.ParseString(data)
+		if err != nil {
+			return string(data)
+		}
+		return str
 	case jsonparser.Number:
-		numberString := cast.ToString(data)
-		numberFloat := cast.ToFloat64(numberString)
-		return numberFloat
+		number, _ := jsonparser.ParseFloat(data)
+		return number
 	case jsonparser.Boolean:
-		return string(data)
-	case jsonparser.Null:
-		return string(data)
+		boolean, _ := jsonparser.ParseBoolean(data)
+		return boolean
+	case jsonparser.Array:
+		array := make([]interface{}, 0)
+		if err := json.Unmarshal(data, &array); err != nil {
+			return nil
+		}
+		return array
+	case jsonparser.Object:
+		object := make(map[string]interface{})
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil
+		}
+		return object
 	}
 	return nil
 }
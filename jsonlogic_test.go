@@ -1,6 +1,7 @@
 package jsonlogic
 
 import (
+	"math"
 	"reflect"
 	"testing"
 
@@ -315,15 +316,39 @@ func TestInArray(t *testing.T) {
 // Cat
 
 // %
-// func TestModolo(t *testing.T) {
-// 	rule := `{"%": [101,2]}`
+func TestModolo(t *testing.T) {
+	rule := `{"%": [101,2]}`
 
-// 	result, _ := Run(rule)
+	result, _ := Run(rule)
+
+	if result != 1.0 {
+		t.Fatalf("rule should return 1, instead returned %v", result)
+	}
+}
+
+func TestModoloSign(t *testing.T) {
+	rules := map[string]float64{
+		`{"%": [-7, 3]}`:     -1,
+		`{"%": [7, -3]}`:     1,
+		`{"%": [5.5, 2]}`:    1.5,
+		`{"%": ["10", "4"]}`: 2,
+	}
+
+	for rule, expected := range rules {
+		result, _ := Run(rule)
+		if result != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v", rule, expected, result)
+		}
+	}
+}
 
-// 	if cast.ToInt(result) != 1 {
-// 		t.Fatalf("rule should return 1, instead returned %s", result)
-// 	}
-// }
+func TestModoloZero(t *testing.T) {
+	result, _ := Run(`{"%": [1, 0]}`)
+
+	if number, ok := result.(float64); !ok || !math.IsNaN(number) {
+		t.Fatalf("rule should return NaN, instead returned %v", result)
+	}
+}
 
 // substr
 
@@ -522,7 +547,7 @@ func TestDivideTrue(t *testing.T) {
 }
 
 func TestPercentageSoftEquals(t *testing.T) {
-	rule := `{"==": [{"%" : [20,50]}, 40]} `
+	rule := `{"==": [{"percentage" : [20,50]}, 40]} `
 
 	// Should return true
 	result, _ := Run(rule)
@@ -533,7 +558,7 @@ func TestPercentageSoftEquals(t *testing.T) {
 }
 
 func TestPercentageTrue(t *testing.T) {
-	rule := `{"%" : [20,50]}`
+	rule := `{"percentage" : [20,50]}`
 
 	// Should return true
 	result, _ := Run(rule)
@@ -544,7 +569,7 @@ func TestPercentageTrue(t *testing.T) {
 }
 
 func TestTruthyTrue(t *testing.T) {
-	rule := `{"!!" : []}`
+	rule := `{"!!" : [1]}`
 	data := `{"a":1,"b":2}`
 
 	// Should return true
@@ -556,7 +581,7 @@ func TestTruthyTrue(t *testing.T) {
 }
 
 func TestNotTruthyTrue(t *testing.T) {
-	rule := `{"!" : []}`
+	rule := `{"!" : [1]}`
 	data := `{"a":1,"b":2}`
 
 	// Should return true
@@ -567,6 +592,26 @@ func TestNotTruthyTrue(t *testing.T) {
 	}
 }
 
+func TestTruthyFirstArgument(t *testing.T) {
+	rules := map[string]bool{
+		`{"!!":[1]}`:        true,
+		`{"!!":["0"]}`:      true,
+		`{"!!":[[]]}`:       false,
+		`{"!!":[]}`:         false,
+		`{"!":[true]}`:      false,
+		`{"!":[[]]}`:        true,
+		`{"!":[]}`:          true,
+		`{"!":{"var":"a"}}`: false,
+	}
+
+	for rule, target := range rules {
+		result, err := Apply(rule, `{"a":1}`)
+		if err != nil || result != target {
+			t.Fatalf("rule %s should return %t, instead returned %v, %v", rule, target, result, err)
+		}
+	}
+}
+
 func TestVarTrue(t *testing.T) {
 	rule := `{"var" : "a"}`
 	data := `{"a":1,"b":2}`
@@ -637,6 +682,41 @@ func TestVarArray(t *testing.T) {
 
 }
 
+func TestVarTypes(t *testing.T) {
+	data := `{"active":true, "deleted":false, "parent":null, "tags":["a","b"], "flags":{"beta":true}, "quote":"say \"hi\""}`
+	rules := map[string]interface{}{
+		`{"var":"active"}`:  true,
+		`{"var":"deleted"}`: false,
+		`{"var":"parent"}`:  nil,
+		`{"var":"tags"}`:    []interface{}{"a", "b"},
+		`{"var":"flags"}`:   map[string]interface{}{"beta": true},
+		`{"var":"quote"}`:   `say "hi"`,
+	}
+
+	for rule, expected := range rules {
+		result, err := Apply(rule, data)
+		if err != nil || !reflect.DeepEqual(result, expected) {
+			t.Fatalf("rule %s should return %#v, instead returned %#v, %v", rule, expected, result, err)
+		}
+	}
+}
+
+func TestVarStrictEqualBool(t *testing.T) {
+	result, _ := Apply(`{"===":[{"var":"active"}, true]}`, `{"active":true}`)
+
+	if result != true {
+		t.Fatalf("rule should return true, instead returned %v", result)
+	}
+}
+
+func TestVarEmptyString(t *testing.T) {
+	result, _ := Apply(`{"var":"name"}`, `{"name":""}`)
+
+	if result != "" {
+		t.Fatalf("rule should return an empty string, instead returned %v", result)
+	}
+}
+
 func TestSoftEqualTrue(t *testing.T) {
 	rule := `{"==" : [ 10, "10" ]}`
 	data := `{}`
@@ -935,3 +1015,465 @@ func TestOrInvalid(t *testing.T) {
 		t.Fatal("rule should return false")
 	}
 }
+
+func TestDeprecatedAndOrIf(t *testing.T) {
+	if !And([]interface{}{true, 1.0, "a"}) || And([]interface{}{true, ""}) {
+		t.Fatal("And should be true only when every value is truthy")
+	}
+	if !Or([]interface{}{0.0, "a"}) || Or([]interface{}{false, nil}) {
+		t.Fatal("Or should be true when any value is truthy")
+	}
+	if result := If([]interface{}{false, "a", 1.0, "b", "c"}); result != "b" {
+		t.Fatalf("If should return b, instead returned %v", result)
+	}
+	if result := If([]interface{}{false, "a", "c"}); result != "c" {
+		t.Fatalf("If should return c, instead returned %v", result)
+	}
+}
+
+// All, None and Some
+
+func TestAll(t *testing.T) {
+	rule := `{"all" : [ [1,2,3], {">":[{"var":""}, 0]} ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToBool(result) != true {
+		t.Fatalf("rule should return true, instead returned %v", result)
+	}
+}
+
+func TestAllFalse(t *testing.T) {
+	rule := `{"all" : [ {"var":"items"}, {">":[{"var":"qty"}, 0]} ]}`
+	data := `{"items":[{"qty":1},{"qty":0},{"qty":3}]}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToBool(result) != false {
+		t.Fatalf("rule should return false, instead returned %v", result)
+	}
+}
+
+func TestAllData(t *testing.T) {
+	rule := `{"all" : [ {"var":"items"}, {">":[{"var":"qty"}, 0]} ]}`
+	data := `{"items":[{"qty":1},{"qty":2},{"qty":3}]}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToBool(result) != true {
+		t.Fatalf("rule should return true, instead returned %v", result)
+	}
+}
+
+func TestAllEmpty(t *testing.T) {
+	rule := `{"all" : [ [], {">":[{"var":""}, 0]} ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToBool(result) != false {
+		t.Fatalf("rule should return false, instead returned %v", result)
+	}
+}
+
+func TestSome(t *testing.T) {
+	rule := `{"some" : [ {"var":"pies"}, {"==":[{"var":"filling"}, "apple"]} ]}`
+	data := `{"pies":[
+		{"filling":"pumpkin","temp":110},
+		{"filling":"rhubarb","temp":210},
+		{"filling":"apple","temp":310}
+	]}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToBool(result) != true {
+		t.Fatalf("rule should return true, instead returned %v", result)
+	}
+}
+
+func TestSomeEmpty(t *testing.T) {
+	rule := `{"some" : [ [], {">":[{"var":""}, 0]} ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToBool(result) != false {
+		t.Fatalf("rule should return false, instead returned %v", result)
+	}
+}
+
+func TestNone(t *testing.T) {
+	rule := `{"none" : [ [-3,-2,-1], {">":[{"var":""}, 0]} ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToBool(result) != true {
+		t.Fatalf("rule should return true, instead returned %v", result)
+	}
+}
+
+func TestNoneFalse(t *testing.T) {
+	rule := `{"none" : [ [-3,-2,1], {">":[{"var":""}, 0]} ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToBool(result) != false {
+		t.Fatalf("rule should return false, instead returned %v", result)
+	}
+}
+
+// Map, Reduce and Filter
+
+func TestMap(t *testing.T) {
+	rule := `{"map":[ {"var":"integers"}, {"*":[{"var":""},2]} ]}`
+	data := `{"integers":[1,2,3,4,5]}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{2.0, 4.0, 6.0, 8.0, 10.0}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [2,4,6,8,10], instead returned %v", result)
+	}
+}
+
+func TestMapObjects(t *testing.T) {
+	rule := `{"map":[ {"var":"items"}, {"var":"sku"} ]}`
+	data := `{"items":[{"sku":"a1"},{"sku":"b2"}]}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{"a1", "b2"}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [a1,b2], instead returned %v", result)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	rule := `{"filter":[ {"var":"integers"}, {">=":[{"var":""},2]} ]}`
+	data := `{"integers":[1,2,3]}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{2.0, 3.0}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [2,3], instead returned %v", result)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	rule := `{"reduce":[
+		{"var":"integers"},
+		{"+":[{"var":"current"}, {"var":"accumulator"}]},
+		0
+	]}`
+	data := `{"integers":[1,2,3,4]}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToFloat64(result) != 10 {
+		t.Fatalf("rule should return 10, instead returned %v", result)
+	}
+}
+
+func TestReduceOrderTotal(t *testing.T) {
+	rule := `{"reduce":[
+		{"var":"lines"},
+		{"+":[{"*":[{"var":"current.qty"}, {"var":"current.price"}]}, {"var":"accumulator"}]},
+		{"var":"shipping"}
+	]}`
+	data := `{"shipping":5,"lines":[{"qty":2,"price":3.5},{"qty":1,"price":10}]}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToFloat64(result) != 22 {
+		t.Fatalf("rule should return 22, instead returned %v", result)
+	}
+}
+
+func TestReduceEmpty(t *testing.T) {
+	rule := `{"reduce":[ [], {"+":[{"var":"current"}, {"var":"accumulator"}]}, 0 ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToFloat64(result) != 0 {
+		t.Fatalf("rule should return 0, instead returned %v", result)
+	}
+}
+
+func TestMissingDotNotation(t *testing.T) {
+	rule := `{"missing":["pie.filling", "pie.crust"]}`
+	data := `{"pie":{"filling":"apple"}}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{"pie.crust"}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [pie.crust], instead returned %v", result)
+	}
+}
+
+func TestMissingSugar(t *testing.T) {
+	rule := `{"missing":"a"}`
+	data := `{"a":"apple"}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [], instead returned %v", result)
+	}
+}
+
+func TestMissingEmptyValues(t *testing.T) {
+	rule := `{"missing":["a", "b", "c"]}`
+	data := `{"a":null, "b":"", "c":0}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{"a", "b"}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [a,b], instead returned %v", result)
+	}
+}
+
+func TestMissingMerge(t *testing.T) {
+	rule := `{"missing" :
+		{ "merge" : [
+		  "vin",
+		  {"if": [{"var":"financing"}, ["apr", "term"], [] ]}
+		]}
+	  }`
+	data := `{"financing":true, "apr":3.5}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{"vin", "term"}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [vin,term], instead returned %v", result)
+	}
+}
+
+// Missing Some
+
+func TestMissingSome(t *testing.T) {
+	rule := `{"missing_some":[1, ["a", "b", "c"]]}`
+	data := `{"a":"apple"}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [], instead returned %v", result)
+	}
+}
+
+func TestMissingSomeNotEnough(t *testing.T) {
+	rule := `{"missing_some":[2, ["a", "b", "c"]]}`
+	data := `{"a":"apple"}`
+
+	result, _ := Apply(rule, data)
+	target := []interface{}{"b", "c"}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return [b,c], instead returned %v", result)
+	}
+}
+
+// Logic and Boolean Operations
+
+func TestIfElseIf(t *testing.T) {
+	rule := `{"if" : [
+		{"<": [{"var":"temp"}, 0] }, "freezing",
+		{"<": [{"var":"temp"}, 100] }, "liquid",
+		"gas"
+	]}`
+	data := `{"temp":55}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToString(result) != "liquid" {
+		t.Fatalf("rule should return liquid, instead returned %v", result)
+	}
+}
+
+func TestIfNoElse(t *testing.T) {
+	rule := `{"if" : [ false, "yes" ]}`
+
+	result, _ := Run(rule)
+
+	if result != nil {
+		t.Fatalf("rule should return nil, instead returned %v", result)
+	}
+}
+
+func TestIfArrayCondition(t *testing.T) {
+	rule := `{"if" :[
+		{"merge": [
+			{"missing":["first_name", "last_name"]},
+			{"missing_some":[1, ["cell_phone", "home_phone"] ]}
+		]},
+		"We require first name, last name, and one phone number.",
+		"OK to proceed"
+	]}`
+	data := `{"first_name":"Bruce", "last_name":"Wayne"}`
+
+	result, _ := Apply(rule, data)
+
+	if cast.ToString(result) != "We require first name, last name, and one phone number." {
+		t.Fatalf("rule should ask for a phone number, instead returned %v", result)
+	}
+}
+
+func TestTernary(t *testing.T) {
+	rule := `{"?:" : [ {"<": [1, 2]}, "yes", "no" ]}`
+
+	result, _ := Run(rule)
+
+	if cast.ToString(result) != "yes" {
+		t.Fatalf("rule should return yes, instead returned %v", result)
+	}
+}
+
+func TestAndReturnsOperand(t *testing.T) {
+	rule := `{"and" : [ true, "a", 3 ]}`
+
+	result, _ := Run(rule)
+
+	if result != 3.0 {
+		t.Fatalf("rule should return 3, instead returned %v", result)
+	}
+}
+
+func TestAndReturnsFalsyOperand(t *testing.T) {
+	rule := `{"and" : [ true, "", 3 ]}`
+
+	result, _ := Run(rule)
+
+	if result != "" {
+		t.Fatalf("rule should return an empty string, instead returned %v", result)
+	}
+}
+
+func TestOrReturnsOperand(t *testing.T) {
+	rule := `{"or" : [ false, 0, "a" ]}`
+
+	result, _ := Run(rule)
+
+	if result != "a" {
+		t.Fatalf("rule should return a, instead returned %v", result)
+	}
+}
+
+func TestShortCircuit(t *testing.T) {
+	calls := 0
+	AddOperator("count_calls", func(rule string, data string) interface{} {
+		calls++
+		return true
+	})
+	defer DefaultEngine.RemoveOperator("count_calls")
+
+	rules := []string{
+		`{"and" : [ false, {"count_calls":[]} ]}`,
+		`{"or" : [ true, {"count_calls":[]} ]}`,
+		`{"if" : [ true, "yes", {"count_calls":[]} ]}`,
+		`{"if" : [ false, {"count_calls":[]}, "no" ]}`,
+		`{"?:" : [ false, {"count_calls":[]}, "no" ]}`,
+	}
+	for _, rule := range rules {
+		Run(rule)
+	}
+
+	if calls != 0 {
+		t.Fatalf("branches not taken should not be evaluated, instead evaluated %d", calls)
+	}
+}
+
+// Arity
+
+func TestArityErrors(t *testing.T) {
+	rules := []string{
+		`{"==":[1]}`,
+		`{"/":[]}`,
+		`{"-":[]}`,
+		`{"in":["a"]}`,
+		`{"substr":["jsonlogic"]}`,
+		`{"missing_some":[1]}`,
+		`{"map":[[1,2]]}`,
+		`{"reduce":[[1,2], {"var":"current"}, 0, 1]}`,
+		`{"log":[]}`,
+	}
+
+	for _, rule := range rules {
+		_, err := Run(rule)
+		if _, ok := err.(*ArityError); !ok {
+			t.Fatalf("rule %s should return an arity error, instead returned %v", rule, err)
+		}
+	}
+}
+
+func TestArityErrorPath(t *testing.T) {
+	_, err := Run(`{"and":[true, {"==":[1]}]}`)
+
+	arity, ok := err.(*ArityError)
+	if !ok {
+		t.Fatalf("rule should return an arity error, instead returned %v", err)
+	}
+
+	if arity.Path != "and[1].==" || arity.Min != 2 || arity.Got != 1 {
+		t.Fatalf("error should be at and[1].== expecting 2 got 1, instead %s", err)
+	}
+}
+
+func TestVarNoArguments(t *testing.T) {
+	rule := `{"var":[]}`
+	data := `"Dolly"`
+
+	result, err := Apply(rule, data)
+
+	if err != nil || cast.ToString(result) != "Dolly" {
+		t.Fatalf("rule should return Dolly, instead returned %v, %v", result, err)
+	}
+}
+
+func TestSubstrOutOfRange(t *testing.T) {
+	rules := map[string]string{
+		`{"substr": ["jsonlogic", 20]}`:     "",
+		`{"substr": ["jsonlogic", -20]}`:    "jsonlogic",
+		`{"substr": ["jsonlogic", 4, 20]}`:  "logic",
+		`{"substr": ["jsonlogic", 4, -20]}`: "",
+		`{"substr": ["jsonlogic", -5, 2]}`:  "lo",
+		`{"substr": ["héllo", 1, 3]}`:       "éll",
+	}
+
+	for rule, target := range rules {
+		result, err := Run(rule)
+		if err != nil || result != target {
+			t.Fatalf("rule %s should return %s, instead returned %v, %v", rule, target, result, err)
+		}
+	}
+}
+
+func FuzzApply(f *testing.F) {
+	seeds := []struct{ rule, data string }{
+		{`{"var":["a"]}`, `{"a":1}`},
+		{`{"var":1}`, `["zero","one"]`},
+		{`{"==":[1]}`, ``},
+		{`{"/":[]}`, ``},
+		{`{"var":[]}`, `"Dolly"`},
+		{`{"substr":["jsonlogic", -20, 5]}`, ``},
+		{`{"and":[{"<":[{"var":"temp"},110]},{"==":[{"var":"pie.filling"},"apple"]}]}`, `{"temp":100,"pie":{"filling":"apple"}}`},
+		{`{"if":[{"missing":["a"]}, "missing", {"cat":["a is ", {"var":"a"}]}]}`, `{"a":"apple"}`},
+		{`{"missing_some":[1, {"merge":["a", ["b"]]}]}`, `{"b":2}`},
+		{`{"all":[{"var":"items"},{">":[{"var":"qty"},0]}]}`, `{"items":[{"qty":1},{"qty":0}]}`},
+		{`{"reduce":[{"var":"n"},{"+":[{"var":"current"},{"var":"accumulator"}]},0]}`, `{"n":[1,2,3]}`},
+		{`{"map":[[1,2],{"*":[{"var":""},2]}]}`, ``},
+		{`{"in":["a", {"var":"list"}]}`, `{"list":"abc"}`},
+		{`{"!!":[[]]}`, `[]`},
+		{`[1, {"a":1, "b":2}, null]`, `null`},
+	}
+	for _, seed := range seeds {
+		f.Add(seed.rule, seed.data)
+	}
+
+	f.Fuzz(func(t *testing.T, rule string, data string) {
+		Apply(rule, data)
+	})
+}
@@ -0,0 +1,459 @@
+package jsonlogic
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// ToMongo translates a rule into a MongoDB filter using the operators of the default engine, see Engine.ToMongo.
+func ToMongo(rule string) (map[string]interface{}, error) {
+	return DefaultEngine.ToMongo(rule)
+}
+
+// ToMongo translates a rule into a MongoDB filter document, where the path of each 'var' is the dot notation path
+// of a field. Comparisons of a 'var' with a literal become query operators such as {"temp":{"$lt":110}},
+// 'and', 'or' and '!' become $and, $or and $nor, 'in' an array $in, 'missing' a test for null or empty fields
+// and a 'var' on its own a test that the field exists and is truthy. Anything else, such as arithmetic, becomes an
+// aggregation expression within $expr, which follows the types and truthiness of MongoDB rather than JsonLogic.
+// Operators with no translation, including custom operators, return a *TranslationError.
+func (e *Engine) ToMongo(rule string) (map[string]interface{}, error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &mongoBuilder{engine: e}
+	return m.query(program.root, "")
+}
+
+// mongoBuilder writes the MongoDB filter of a compiled rule.
+type mongoBuilder struct {
+	engine *Engine
+}
+
+// mongoComparisons are the query operators of comparisons, along with those used when the arguments are swapped.
+var mongoComparisons = map[string][2]string{
+	"==":  {"$eq", "$eq"},
+	"===": {"$eq", "$eq"},
+	"!=":  {"$ne", "$ne"},
+	"!==": {"$ne", "$ne"},
+	"<":   {"$lt", "$gt"},
+	"<=":  {"$lte", "$gte"},
+	">":   {"$gt", "$lt"},
+	">=":  {"$gte", "$lte"},
+}
+
+func (m *mongoBuilder) untranslatable(key string, path string, reason string) error {
+	return &TranslationError{Target: "MongoDB", Operator: key, Path: path, Reason: reason}
+}
+
+// builtIn checks the operator is a built in one with the right number of arguments.
+func (m *mongoBuilder) builtIn(n *operatorNode) error {
+	_, legacy := m.engine.operator(n.key)
+	_, custom := m.engine.operation(n.key)
+	if legacy || custom {
+		return m.untranslatable(n.key, n.path(), "custom operators have no translation")
+	}
+
+	arity, ok := operatorArity[n.key]
+	if !ok {
+		return &UnknownOperatorError{Operator: n.key, Path: n.path()}
+	}
+	if len(n.args) < arity.min || (arity.max >= 0 && len(n.args) > arity.max) {
+		return &ArityError{Operator: n.key, Path: n.path(), Min: arity.min, Max: arity.max, Got: len(n.args)}
+	}
+	return nil
+}
+
+// query translates a node into a filter document, falling back to $expr for anything the query operators can't express.
+func (m *mongoBuilder) query(n node, path string) (map[string]interface{}, error) {
+	operator, ok := n.(*operatorNode)
+	if !ok {
+		expr, err := m.expr(n, path)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"$expr": expr}, nil
+	}
+	if err := m.builtIn(operator); err != nil {
+		return nil, err
+	}
+
+	switch operator.key {
+	case "and", "or":
+		if len(operator.args) > 0 {
+			return m.logic(operator)
+		}
+	case "!":
+		if len(operator.args) > 0 {
+			filter, err := m.query(operator.args[0], argumentPath(operator.path(), 0))
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{"$nor": []interface{}{filter}}, nil
+		}
+	case "!!":
+		if len(operator.args) > 0 {
+			return m.query(operator.args[0], argumentPath(operator.path(), 0))
+		}
+	case "var":
+		field, ok, err := m.field(operator)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return map[string]interface{}{field: map[string]interface{}{"$exists": true, "$nin": []interface{}{nil, false, 0.0, ""}}}, nil
+		}
+	case "missing":
+		if keys, ok := missingKeyArgs(operator.args); ok && len(keys) > 0 {
+			return m.missing(operator, keys)
+		}
+	case "in":
+		if filter, ok, err := m.in(operator); ok || err != nil {
+			return filter, err
+		}
+	case "==", "===", "!=", "!==", "<", "<=", ">", ">=":
+		if filter, ok, err := m.comparison(operator); ok || err != nil {
+			return filter, err
+		}
+	}
+
+	expr, err := m.expr(operator, operator.path())
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"$expr": expr}, nil
+}
+
+// logic translates 'and' and 'or' into $and and $or of their arguments.
+func (m *mongoBuilder) logic(n *operatorNode) (map[string]interface{}, error) {
+	filters := make([]interface{}, 0, len(n.args))
+	for i, arg := range n.args {
+		filter, err := m.query(arg, argumentPath(n.path(), i))
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 1 {
+		return filters[0].(map[string]interface{}), nil
+	}
+	return map[string]interface{}{"$" + n.key: filters}, nil
+}
+
+// missing translates 'missing' into whether any of the fields are absent, null or empty.
+func (m *mongoBuilder) missing(n *operatorNode, keys []interface{}) (map[string]interface{}, error) {
+	filters := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		field, err := m.fieldPath(n, varPath(key))
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, map[string]interface{}{field: map[string]interface{}{"$in": []interface{}{nil, ""}}})
+	}
+
+	if len(filters) == 1 {
+		return filters[0].(map[string]interface{}), nil
+	}
+	return map[string]interface{}{"$or": filters}, nil
+}
+
+// comparison translates a comparison of a 'var' with a literal, or a literal between two, into a query operator,
+// reporting false if it isn't one.
+func (m *mongoBuilder) comparison(n *operatorNode) (map[string]interface{}, bool, error) {
+	operators := mongoComparisons[n.key]
+
+	if len(n.args) == 3 {
+		low, lowOk := literal(n.args[0])
+		high, highOk := literal(n.args[2])
+		variable, ok := n.args[1].(*operatorNode)
+		if !lowOk || !highOk || !ok {
+			return nil, false, nil
+		}
+		field, ok, err := m.field(variable)
+		if !ok || err != nil {
+			return nil, ok, err
+		}
+		return map[string]interface{}{field: map[string]interface{}{operators[1]: low, operators[0]: high}}, true, nil
+	}
+
+	for i, swapped := range []bool{false, true} {
+		variable, ok := n.args[i].(*operatorNode)
+		value, literalOk := literal(n.args[1-i])
+		if !ok || !literalOk {
+			continue
+		}
+		field, ok, err := m.field(variable)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+
+		operator := operators[0]
+		if swapped {
+			operator = operators[1]
+		}
+		return map[string]interface{}{field: map[string]interface{}{operator: value}}, true, nil
+	}
+	return nil, false, nil
+}
+
+// in translates 'in' of a 'var' within a literal array into $in, and of a literal within a 'var' into either an
+// array field with an element equal to it or any other field containing its text, reporting false if it is neither.
+// The literal is compared with $eq and escaped within $regex so the rule can't become query operators of the field.
+func (m *mongoBuilder) in(n *operatorNode) (map[string]interface{}, bool, error) {
+	if variable, ok := n.args[0].(*operatorNode); ok {
+		values, ok := literal(n.args[1])
+		if array, isArray := values.([]interface{}); ok && isArray {
+			field, ok, err := m.field(variable)
+			if !ok || err != nil {
+				return nil, ok, err
+			}
+			return map[string]interface{}{field: map[string]interface{}{"$in": array}}, true, nil
+		}
+	}
+
+	if variable, ok := n.args[1].(*operatorNode); ok {
+		if value, ok := literal(n.args[0]); ok {
+			field, ok, err := m.field(variable)
+			if !ok || err != nil {
+				return nil, ok, err
+			}
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil, false, m.untranslatable(n.key, n.path(), "only a string, number, boolean or null can be found within a field")
+			}
+			return map[string]interface{}{"$or": []interface{}{
+				map[string]interface{}{field: map[string]interface{}{"$eq": value}},
+				map[string]interface{}{field: map[string]interface{}{
+					"$regex": regexp.QuoteMeta(cast.ToString(value)),
+					"$not":   map[string]interface{}{"$type": "array"},
+				}},
+			}}, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// field returns the field of a 'var' with a literal key and no fallback, reporting false for anything else.
+func (m *mongoBuilder) field(n *operatorNode) (string, bool, error) {
+	if n.key != "var" || len(n.args) != 1 || m.builtIn(n) != nil {
+		return "", false, nil
+	}
+	key, ok := n.args[0].(*literalNode)
+	if !ok {
+		return "", false, nil
+	}
+
+	field, err := m.fieldPath(n, varPath(key.value))
+	return field, err == nil, err
+}
+
+// fieldPath checks the path of a 'var' is a field, so a rule can't use query operators such as $where as fields.
+func (m *mongoBuilder) fieldPath(n *operatorNode, path string) (string, error) {
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" || strings.HasPrefix(segment, "$") {
+			return "", m.untranslatable(n.key, n.path(), "the path "+path+" isn't a field")
+		}
+	}
+	return path, nil
+}
+
+// expr translates a node into an aggregation expression.
+func (m *mongoBuilder) expr(n node, path string) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		if text, ok := n.value.(string); ok && strings.HasPrefix(text, "$") {
+			return map[string]interface{}{"$literal": text}, nil
+		}
+		return n.value, nil
+	case *objectNode:
+		value, _ := literal(n)
+		return map[string]interface{}{"$literal": value}, nil
+	case *arrayNode:
+		items := make([]interface{}, 0, len(n.items))
+		for i, item := range n.items {
+			expr, err := m.expr(item, argumentPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, expr)
+		}
+		return items, nil
+	case *operatorNode:
+		return m.operator(n)
+	}
+	return nil, m.untranslatable("", path, "unknown value")
+}
+
+// exprs translates the arguments of an operator into aggregation expressions.
+func (m *mongoBuilder) exprs(n *operatorNode) ([]interface{}, error) {
+	exprs := make([]interface{}, 0, len(n.args))
+	for i, arg := range n.args {
+		expr, err := m.expr(arg, argumentPath(n.path(), i))
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+// mongoOperators are the aggregation operators of the built in operators which translate directly.
+var mongoOperators = map[string]string{
+	"==": "$eq", "===": "$eq", "!=": "$ne", "!==": "$ne", ">": "$gt", ">=": "$gte", "<": "$lt", "<=": "$lte",
+	"and": "$and", "or": "$or", "+": "$add", "*": "$multiply", "/": "$divide", "%": "$mod", "max": "$max", "min": "$min",
+}
+
+func (m *mongoBuilder) operator(n *operatorNode) (interface{}, error) {
+	if err := m.builtIn(n); err != nil {
+		return nil, err
+	}
+
+	switch n.key {
+	case "var":
+		return m.variable(n)
+	case "missing", "missing_some":
+		return m.missingExpr(n)
+	case "map", "filter", "reduce", "all", "some", "none", "merge", "substr", "log", "percentage":
+		return nil, m.untranslatable(n.key, n.path(), "it has no translation")
+	case "!", "!!":
+		if len(n.args) == 0 {
+			return nil, m.untranslatable(n.key, n.path(), "it has no argument")
+		}
+	}
+
+	args, err := m.exprs(n)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.key {
+	case "<", "<=":
+		if len(args) == 3 {
+			operator := mongoOperators[n.key]
+			return map[string]interface{}{"$and": []interface{}{
+				map[string]interface{}{operator: []interface{}{args[0], args[1]}},
+				map[string]interface{}{operator: []interface{}{args[1], args[2]}},
+			}}, nil
+		}
+	case "and", "or":
+		if len(args) == 0 {
+			return false, nil
+		}
+	case "!":
+		return map[string]interface{}{"$not": []interface{}{args[0]}}, nil
+	case "!!":
+		return map[string]interface{}{"$and": []interface{}{args[0]}}, nil
+	case "if", "?:":
+		return m.conditional(args), nil
+	case "in":
+		if isLiteralString(n.args[1]) {
+			return map[string]interface{}{"$gte": []interface{}{map[string]interface{}{"$indexOfCP": []interface{}{args[1], args[0]}}, 0.0}}, nil
+		}
+		return map[string]interface{}{"$in": []interface{}{args[0], args[1]}}, nil
+	case "-":
+		if len(args) == 1 {
+			return map[string]interface{}{"$multiply": []interface{}{-1.0, args[0]}}, nil
+		}
+		result := args[0]
+		for _, arg := range args[1:] {
+			result = map[string]interface{}{"$subtract": []interface{}{result, arg}}
+		}
+		return result, nil
+	case "cat":
+		for i, arg := range args {
+			if !isLiteralString(n.args[i]) {
+				args[i] = map[string]interface{}{"$ifNull": []interface{}{map[string]interface{}{"$toString": arg}, ""}}
+			}
+		}
+		return map[string]interface{}{"$concat": args}, nil
+	}
+
+	return map[string]interface{}{mongoOperators[n.key]: args}, nil
+}
+
+// isLiteralString reports whether a node is a literal string, which needn't be converted to one.
+func isLiteralString(n node) bool {
+	value, ok := literal(n)
+	_, text := value.(string)
+	return ok && text
+}
+
+// variable translates a 'var' into the field path, along with its fallback when it has one.
+func (m *mongoBuilder) variable(n *operatorNode) (interface{}, error) {
+	if len(n.args) == 0 {
+		return nil, m.untranslatable(n.key, n.path(), "all of the data isn't a field")
+	}
+	key, ok := n.args[0].(*literalNode)
+	if !ok {
+		return nil, m.untranslatable(n.key, n.path(), "the key is computed")
+	}
+
+	field, err := m.fieldPath(n, varPath(key.value))
+	if err != nil {
+		return nil, err
+	}
+	if len(n.args) == 1 {
+		return "$" + field, nil
+	}
+
+	fallback, err := m.expr(n.args[1], argumentPath(n.path(), 1))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"$ifNull": []interface{}{"$" + field, fallback}}, nil
+}
+
+// missingExpr translates 'missing' into whether any of the fields are absent, null or empty, and 'missing_some'
+// into whether fewer than the number needed aren't.
+func (m *mongoBuilder) missingExpr(n *operatorNode) (interface{}, error) {
+	args := n.args
+	if n.key == "missing_some" {
+		args = n.args[1:]
+	}
+	keys, ok := missingKeyArgs(args)
+	if !ok {
+		return nil, m.untranslatable(n.key, n.path(), "the keys are computed")
+	}
+
+	missing := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		field, err := m.fieldPath(n, varPath(key))
+		if err != nil {
+			return nil, err
+		}
+		value := map[string]interface{}{"$ifNull": []interface{}{"$" + field, nil}}
+		missing = append(missing, map[string]interface{}{"$in": []interface{}{value, []interface{}{nil, ""}}})
+	}
+
+	if n.key == "missing" {
+		return map[string]interface{}{"$or": missing}, nil
+	}
+
+	need, err := m.expr(n.args[0], argumentPath(n.path(), 0))
+	if err != nil {
+		return nil, err
+	}
+	present := make([]interface{}, 0, len(missing))
+	for _, condition := range missing {
+		present = append(present, map[string]interface{}{"$cond": []interface{}{condition, 0.0, 1.0}})
+	}
+	return map[string]interface{}{"$lt": []interface{}{map[string]interface{}{"$add": present}, need}}, nil
+}
+
+// conditional translates 'if' into nested $cond, the last of which defaults to null without a final unpaired argument.
+func (m *mongoBuilder) conditional(args []interface{}) interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	if len(args) == 1 {
+		return args[0]
+	}
+	return map[string]interface{}{"$cond": []interface{}{args[0], args[1], m.conditional(args[2:])}}
+}
@@ -0,0 +1,73 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestToMongo(t *testing.T) {
+	rules := map[string]string{
+		`{"and":[{"<":[{"var":"temp"}, 110]}, {"==":[{"var":"pie.filling"}, "apple"]}]}`: `{"$and":[{"temp":{"$lt":110}}, {"pie.filling":{"$eq":"apple"}}]}`,
+		`{"or":[{"!=":[{"var":"name"}, null]}, {"!":{"var":"temp"}}]}`:                   `{"$or":[{"name":{"$ne":null}}, {"$nor":[{"temp":{"$exists":true, "$nin":[null, false, 0, ""]}}]}]}`,
+		`{"<":[5, {"var":"temp"}]}`:                                                           `{"temp":{"$gt":5}}`,
+		`{"<=":[1, {"var":"temp"}, 10]}`:                                                      `{"temp":{"$gte":1, "$lte":10}}`,
+		`{"in":[{"var":"pie.filling"}, ["apple", "cherry"]]}`:                                 `{"pie.filling":{"$in":["apple", "cherry"]}}`,
+		`{"in":["fruit", {"var":"tags"}]}`:                                                    `{"$or":[{"tags":{"$eq":"fruit"}}, {"tags":{"$regex":"fruit", "$not":{"$type":"array"}}}]}`,
+		`{"in":["ell", {"var":"name"}]}`:                                                      `{"$or":[{"name":{"$eq":"ell"}}, {"name":{"$regex":"ell", "$not":{"$type":"array"}}}]}`,
+		`{"in":["^a.b*(c)$", {"var":"name"}]}`:                                                `{"$or":[{"name":{"$eq":"^a.b*(c)$"}}, {"name":{"$regex":"\\^a\\.b\\*\\(c\\)\\$", "$not":{"$type":"array"}}}]}`,
+		`{"in":[1.5, {"var":"tags"}]}`:                                                        `{"$or":[{"tags":{"$eq":1.5}}, {"tags":{"$regex":"1\\.5", "$not":{"$type":"array"}}}]}`,
+		`{"and":[{"!!":{"var":"tags"}}]}`:                                                     `{"tags":{"$exists":true, "$nin":[null, false, 0, ""]}}`,
+		`{"missing":["name", "temp"]}`:                                                        `{"$or":[{"name":{"$in":[null, ""]}}, {"temp":{"$in":[null, ""]}}]}`,
+		`{">":[{"*":[{"var":"pie.price"}, {"-":[{"var":"temp"}, 2, 1]}]}, {"-":[5]}]}`:        `{"$expr":{"$gt":[{"$multiply":["$pie.price", {"$subtract":[{"$subtract":["$temp", 2]}, 1]}]}, {"$multiply":[-1, 5]}]}}`,
+		`{"==":[{"var":"temp"}, {"var":"pie.price"}]}`:                                        `{"$expr":{"$eq":["$temp", "$pie.price"]}}`,
+		`{"==":[{"cat":[{"var":"name"}, "-", "$x"]}, "pie-$x"]}`:                              `{"$expr":{"$eq":[{"$concat":[{"$ifNull":[{"$toString":"$name"}, ""]}, "-", {"$literal":"$x"}]}, "pie-$x"]}}`,
+		`{"<":[{"var":["temp", 0]}, 1, {"max":[2, {"var":"pie.price"}]}]}`:                    `{"$expr":{"$and":[{"$lt":[{"$ifNull":["$temp", 0]}, 1]}, {"$lt":[1, {"$max":[2, "$pie.price"]}]}]}}`,
+		`{"if":[{"var":"tags"}, {"in":["a", "abc"]}, {"==":[{"%":[{"var":"temp"}, 2]}, 0]}]}`: `{"$expr":{"$cond":["$tags", {"$gte":[{"$indexOfCP":["abc", "a"]}, 0]}, {"$eq":[{"$mod":["$temp", 2]}, 0]}]}}`,
+		`{"!":{"missing_some":[1, ["name", "temp"]]}}`:                                        `{"$nor":[{"$expr":{"$lt":[{"$add":[{"$cond":[{"$in":[{"$ifNull":["$name", null]}, [null, ""]]}, 0, 1]}, {"$cond":[{"$in":[{"$ifNull":["$temp", null]}, [null, ""]]}, 0, 1]}]}, 1]}}]}`,
+		`true`: `{"$expr":true}`,
+	}
+
+	for rule, expected := range rules {
+		var want map[string]interface{}
+		if err := json.Unmarshal([]byte(expected), &want); err != nil {
+			t.Fatal(err)
+		}
+
+		filter, err := ToMongo(rule)
+		if err != nil || !reflect.DeepEqual(filter, want) {
+			got, _ := json.Marshal(filter)
+			t.Fatalf("rule %s should translate to %s, instead returned %s, %v", rule, expected, got, err)
+		}
+	}
+}
+
+func TestToMongoTranslationError(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("double", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return args[0].(float64) * 2, nil
+	})
+
+	rules := map[string]string{
+		`{"and":[true, {"some":[{"var":"tags"}, {"==":[{"var":""}, "a"]}]}]}`: "and[1].some",
+		`{"==":[{"var":"$where"}, 1]}`:                                        "==[0].var",
+		`{"==":[{"var":"pie..price"}, {"var":"temp"}]}`:                       "==[0].var",
+		`{"missing":["temp", "$gt"]}`:                                         "missing",
+		`{"==":[{"var":{"cat":["te", "mp"]}}, 1]}`:                            "==[0].var",
+		`{"==":[{"double":{"var":"temp"}}, 2]}`:                               "==[0].double",
+		`{"substr":[{"var":"name"}, 1]}`:                                      "substr",
+		`{"in":[{"$ne":null, "$exists":true}, {"var":"password"}]}`:           "in",
+		`{"in":[{"$regex":"^a", "$options":"i"}, {"var":"name"}]}`:            "in",
+		`{"in":[["a"], {"var":"tags"}]}`:                                      "in",
+	}
+
+	for rule, path := range rules {
+		_, err := engine.ToMongo(rule)
+
+		var translation *TranslationError
+		if !errors.As(err, &translation) || translation.Path != path || translation.Target != "MongoDB" {
+			t.Fatalf("rule %s should fail to translate at %s, instead returned %v", rule, path, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Operation is a custom operator which receives its arguments already evaluated.
+// A returned error stops the evaluation and is returned from Apply.
+type Operation func(ctx *Context, args []interface{}) (interface{}, error)
+
+// LazyOperation is a custom operator which receives its arguments unevaluated, so it can decide which of them
+// to evaluate and against what data, as 'if' and 'map' do. A returned error stops the evaluation and is returned from Apply.
+type LazyOperation func(ctx *Context, args []Argument) (interface{}, error)
+
+// Context is the evaluation a custom operator is running in.
+type Context struct {
+	ev   *evaluation
+	data interface{}
+}
+
+// Engine returns the engine the rule is being evaluated with.
+func (c *Context) Engine() *Engine {
+	return c.ev.engine
+}
+
+// Context returns the context of the evaluation, which is done once the evaluation should stop.
+// Operators which block, such as those making requests, should give up once it is done.
+func (c *Context) Context() context.Context {
+	return c.ev.ctx
+}
+
+// Data returns the data the operator is being evaluated against.
+// Within operators such as 'map' and 'filter' this is the current element rather than the data passed to Apply.
+func (c *Context) Data() interface{} {
+	raw, ok := c.data.(jsonData)
+	if !ok {
+		return c.data
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	return value
+}
+
+// Argument is an unevaluated argument of a LazyOperation.
+type Argument struct {
+	ctx  *Context
+	node node
+}
+
+// Evaluate evaluates the argument against the same data as the operator.
+func (a Argument) Evaluate() (interface{}, error) {
+	return a.node.eval(a.ctx.ev, a.ctx.data)
+}
+
+// EvaluateWith evaluates the argument against other data, as 'map' does with each element of an array.
+func (a Argument) EvaluateWith(data interface{}) (interface{}, error) {
+	return evalScoped(a.ctx.ev, a.node, data)
+}
+
+// eager adapts an Operation into a LazyOperation which evaluates all of its arguments up front.
+func eager(operation Operation) LazyOperation {
+	return func(ctx *Context, args []Argument) (interface{}, error) {
+		values := make([]interface{}, 0, len(args))
+		for _, arg := range args {
+			value, err := arg.Evaluate()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return operation(ctx, values)
+	}
+}
+
+// AddOperation registers a custom operator with the default engine which receives its arguments already evaluated.
+func AddOperation(key string, operation Operation) {
+	DefaultEngine.AddOperation(key, operation)
+}
+
+// AddLazyOperation registers a custom operator with the default engine which evaluates its own arguments.
+func AddLazyOperation(key string, operation LazyOperation) {
+	DefaultEngine.AddLazyOperation(key, operation)
+}
@@ -0,0 +1,134 @@
+package jsonlogic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cast"
+)
+
+func TestAddOperation(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("sum", func(ctx *Context, args []interface{}) (interface{}, error) {
+		total := 0.0
+		for _, arg := range args {
+			total += cast.ToFloat64(arg)
+		}
+		return total, nil
+	})
+
+	result, err := engine.Apply(`{"sum":[1, {"var":"a"}, {"+":[1, 2]}]}`, `{"a":4}`)
+	if err != nil {
+		t.Fatalf("rule should evaluate, instead returned %s", err)
+	}
+
+	if result != 8.0 {
+		t.Fatalf("rule should return 8, instead returned %v", result)
+	}
+}
+
+func TestAddOperationError(t *testing.T) {
+	errNegative := errors.New("negative")
+	engine := NewEngine()
+	engine.AddOperation("sqrt", func(ctx *Context, args []interface{}) (interface{}, error) {
+		if cast.ToFloat64(args[0]) < 0 {
+			return nil, errNegative
+		}
+		return args[0], nil
+	})
+
+	result, err := engine.Run(`{"and":[true, {"map":[[4, -1], {"sqrt":{"var":""}}]}]}`)
+	if !errors.Is(err, errNegative) {
+		t.Fatalf("rule should return the operator error, instead returned %v", err)
+	}
+
+	if result != false {
+		t.Fatalf("rule should return false on error, instead returned %v", result)
+	}
+}
+
+func TestAddLazyOperation(t *testing.T) {
+	engine := NewEngine()
+	engine.AddLazyOperation("first", func(ctx *Context, args []Argument) (interface{}, error) {
+		for _, arg := range args {
+			value, err := arg.Evaluate()
+			if err != nil || value != nil {
+				return value, err
+			}
+		}
+		return nil, nil
+	})
+	engine.AddOperation("fail", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return nil, errors.New("should not be evaluated")
+	})
+
+	result, err := engine.Apply(`{"first":[{"var":"a"}, {"var":"b"}, {"fail":[]}]}`, `{"b":"bee"}`)
+	if err != nil {
+		t.Fatalf("rule should evaluate, instead returned %s", err)
+	}
+
+	if result != "bee" {
+		t.Fatalf("rule should return bee, instead returned %v", result)
+	}
+}
+
+func TestArgumentEvaluateWith(t *testing.T) {
+	engine := NewEngine()
+	engine.AddLazyOperation("count_if", func(ctx *Context, args []Argument) (interface{}, error) {
+		items, err := args[0].Evaluate()
+		if err != nil {
+			return nil, err
+		}
+
+		count := 0
+		for _, item := range cast.ToSlice(items) {
+			match, err := args[1].EvaluateWith(item)
+			if err != nil {
+				return nil, err
+			}
+			if match == true {
+				count++
+			}
+		}
+		return count, nil
+	})
+
+	result, _ := engine.Apply(`{"count_if":[{"var":"items"}, {">":[{"var":"qty"}, 1]}]}`, `{"items":[{"qty":1},{"qty":2},{"qty":3}]}`)
+	if result != 2 {
+		t.Fatalf("rule should return 2, instead returned %v", result)
+	}
+}
+
+func TestContextData(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("data", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return ctx.Data(), nil
+	})
+
+	result, _ := engine.Apply(`{"map":[{"var":"items"}, {"data":[]}]}`, `{"items":[{"a":1}, 2]}`)
+	target := []interface{}{map[string]interface{}{"a": 1.0}, 2.0}
+
+	if !reflect.DeepEqual(result, target) {
+		t.Fatalf("rule should return each element, instead returned %v", result)
+	}
+}
+
+func TestAddOperationReplacesOperator(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("op", func(rule string, data string) interface{} {
+		return "operator"
+	})
+	engine.AddOperation("op", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return "operation", nil
+	})
+
+	result, _ := engine.Run(`{"op":[]}`)
+	if result != "operation" {
+		t.Fatalf("rule should return operation, instead returned %v", result)
+	}
+
+	if !reflect.DeepEqual(engine.Operators(), []string{"op"}) {
+		t.Fatalf("engine should only have op, instead has %v", engine.Operators())
+	}
+}
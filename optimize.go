@@ -0,0 +1,383 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Limits of the evaluations used to fold constants when compiling, so a rule can't make compiling it expensive.
+// The operations are shared by every fold of the rule, counting the values of the results along with the operators
+// evaluated, and once they are used up the rest of the rule is left to be evaluated.
+const (
+	foldOperations = 10000
+	foldArraySize  = 10000
+)
+
+// optimizer rewrites a compiled rule into a smaller one which gives exactly the same results. Nodes are never
+// modified, a node which changes is copied, so the rule as written is kept alongside the optimized one.
+type optimizer struct {
+	engine *Engine
+	ctx    context.Context
+	// builtIns are the keys of the built in operators within the rule, the optimized rule is only used while
+	// none of them are replaced by custom operators
+	builtIns map[string]bool
+	// operations are those left for folding constants
+	operations int
+}
+
+// optimize returns the optimized rule along with the built in operators it relies on, or nil if nothing changed.
+// Associative operators nested within themselves are flattened, operators of constants are folded into their
+// result using the built in operators and branches which can never be taken are dropped.
+func optimize(ctx context.Context, engine *Engine, root node) (node, []string) {
+	o := &optimizer{engine: engine, ctx: ctx, builtIns: make(map[string]bool), operations: foldOperations}
+	optimized := o.node(root)
+	if optimized == root {
+		return nil, nil
+	}
+
+	builtIns := make([]string, 0, len(o.builtIns))
+	for key := range o.builtIns {
+		builtIns = append(builtIns, key)
+	}
+	return optimized, builtIns
+}
+
+func (o *optimizer) node(n node) node {
+	switch n := n.(type) {
+	case *operatorNode:
+		return o.operator(n)
+	case *arrayNode:
+		items, changed := o.nodes(n.items)
+		if changed {
+			return &arrayNode{items: items, at: n.at}
+		}
+	}
+	return n
+}
+
+// nodes optimizes each of the nodes, reporting whether any of them changed.
+func (o *optimizer) nodes(nodes []node) ([]node, bool) {
+	optimized := make([]node, 0, len(nodes))
+	changed := false
+	for _, n := range nodes {
+		item := o.node(n)
+		optimized = append(optimized, item)
+		changed = changed || item != n
+	}
+	return optimized, changed
+}
+
+func (o *optimizer) operator(n *operatorNode) node {
+	// Custom operators registered with AddOperator parse their own arguments
+	if _, ok := o.engine.operator(n.key); ok {
+		return n
+	}
+
+	args, changed := o.nodes(n.args)
+	optimized := n
+	if changed {
+		optimized = &operatorNode{key: n.key, args: args, rule: n.rule, at: n.at}
+	}
+
+	if !o.builtIn(n.key) {
+		return optimized
+	}
+	o.builtIns[n.key] = true
+
+	arity := operatorArity[n.key]
+	if len(args) < arity.min || (arity.max >= 0 && len(args) > arity.max) {
+		return optimized
+	}
+
+	switch n.key {
+	case "and", "or":
+		return o.logic(optimized)
+	case "if", "?:":
+		return o.branches(optimized)
+	case "cat":
+		return o.fold(o.concat(o.flatten(optimized, 0)))
+	case "merge":
+		return o.fold(o.flatten(optimized, 0))
+	case "!!":
+		// The result of '!!' is already a boolean
+		if len(args) == 1 {
+			if inner, ok := args[0].(*operatorNode); ok && inner.key == "!!" {
+				return inner
+			}
+		}
+	}
+	return o.fold(optimized)
+}
+
+// builtIn reports whether the key is a built in operator which isn't replaced by a custom operator.
+func (o *optimizer) builtIn(key string) bool {
+	_, ok := operatorArity[key]
+	_, custom := o.engine.operation(key)
+	return ok && !custom
+}
+
+// flatten moves the arguments of operators nested within an operator of the same key into it, where the nested
+// operator has at least min arguments.
+func (o *optimizer) flatten(n *operatorNode, min int) *operatorNode {
+	args := make([]node, 0, len(n.args))
+	changed := false
+	for _, arg := range n.args {
+		if inner, ok := arg.(*operatorNode); ok && inner.key == n.key && len(inner.args) >= min {
+			args = append(args, inner.args...)
+			changed = true
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	if !changed {
+		return n
+	}
+	return &operatorNode{key: n.key, args: args, rule: n.rule, at: n.at}
+}
+
+// concat joins the constants next to each other within 'cat' into a single string, dropping it when it is empty.
+func (o *optimizer) concat(n *operatorNode) *operatorNode {
+	args := make([]node, 0, len(n.args))
+	var run []interface{}
+	join := func() {
+		if text := Cat(run); text != "" {
+			args = append(args, &literalNode{value: text})
+		}
+		run = nil
+	}
+
+	for _, arg := range n.args {
+		if value, ok := literal(arg); ok {
+			run = append(run, value)
+			continue
+		}
+		join()
+		args = append(args, arg)
+	}
+	join()
+
+	if len(args) == len(n.args) {
+		return n
+	}
+	return &operatorNode{key: n.key, args: args, rule: n.rule, at: n.at}
+}
+
+// logic flattens 'and' and 'or', ending them at the first constant which decides them and dropping constants
+// which don't, other than the last as that is the result when no other argument decides them.
+func (o *optimizer) logic(n *operatorNode) node {
+	// An empty 'and' nested within another is null rather than nothing, so only those with arguments are flattened
+	flattened := o.flatten(n, 1)
+	decides := truthy
+	if n.key == "and" {
+		decides = func(value interface{}) bool { return !truthy(value) }
+	}
+
+	args := make([]node, 0, len(flattened.args))
+	for i, arg := range flattened.args {
+		value, ok := literal(arg)
+		if ok && !decides(value) && i < len(flattened.args)-1 {
+			continue
+		}
+		args = append(args, arg)
+		if ok && decides(value) {
+			break
+		}
+	}
+
+	switch {
+	case len(args) == 1:
+		return args[0]
+	case len(args) == len(flattened.args):
+		return o.fold(flattened)
+	}
+	return o.fold(&operatorNode{key: n.key, args: args, rule: n.rule, at: n.at})
+}
+
+// branches drops the branches of 'if' whose condition is a falsy constant and ends it at the first whose condition
+// is a truthy constant, the branch of which becomes the final unpaired argument.
+func (o *optimizer) branches(n *operatorNode) node {
+	args := make([]node, 0, len(n.args))
+	i := 0
+	for ; i+1 < len(n.args); i += 2 {
+		condition, ok := literal(n.args[i])
+		if !ok {
+			args = append(args, n.args[i], n.args[i+1])
+			continue
+		}
+		if truthy(condition) {
+			args = append(args, n.args[i+1])
+			break
+		}
+	}
+	if i+1 == len(n.args) {
+		args = append(args, n.args[i])
+	}
+
+	switch len(args) {
+	case 0:
+		return &literalNode{value: nil}
+	case 1:
+		return args[0]
+	case len(n.args):
+		return n
+	}
+	return &operatorNode{key: n.key, args: args, rule: n.rule, at: n.at}
+}
+
+// fold replaces an operator whose arguments are constants with its result, leaving it as it is when it fails
+// so the error is returned when the rule is evaluated. The rules array operations run against each element
+// only see the element, so they needn't be constant, only free of operators which could do something else.
+func (o *optimizer) fold(n *operatorNode) node {
+	switch n.key {
+	case "var", "missing", "missing_some", "log":
+		return n
+	}
+	if o.operations <= 0 || o.ctx.Err() != nil {
+		return n
+	}
+
+	// The cost is the number of nodes evaluated, where the rule of an array operation is evaluated for each element.
+	// Looking through the arguments counts towards it even when they turn out to be too costly to fold.
+	scanned, cost, elements := 0, 0, 0
+	for i, arg := range n.args {
+		scoped := i == 1 && scopedOperators[n.key]
+		if !scoped {
+			value, ok := literal(arg)
+			if !ok {
+				return n
+			}
+			if i == 0 {
+				_, elements = isArray(value)
+			}
+		}
+
+		size := nodeSize(arg, o.operations)
+		scanned += size
+		cost += size
+		if scoped {
+			cost += size * (elements - 1)
+		}
+	}
+	if cost > o.operations {
+		o.operations -= scanned
+		return n
+	}
+	if scopedOperators[n.key] && len(n.args) > 1 && !o.pure(n.args[1]) {
+		return n
+	}
+	o.operations -= cost
+
+	ev := newEvaluation(o.ctx, o.engine, []Option{WithMaxOperations(o.operations), WithMaxArraySize(foldArraySize)})
+	result, err := runOperator(ev, n.key, n.args, nil)
+	if err == nil {
+		err = ev.checkSize(result, n.at)
+	}
+	o.operations -= ev.operations
+	if err != nil {
+		return n
+	}
+
+	// Each value of the result becomes a node, which would grow exponentially for 'map' nested within itself
+	size := valueSize(result, o.operations)
+	if size > o.operations {
+		return n
+	}
+	o.operations -= size
+
+	folded, ok := valueNode(result, n.at)
+	if !ok {
+		return n
+	}
+	return folded
+}
+
+// scopedOperators are the array operations, whose second argument is run against each element of the first.
+var scopedOperators = map[string]bool{
+	"all": true, "some": true, "none": true, "map": true, "filter": true, "reduce": true,
+}
+
+// pure reports whether a rule only uses built in operators which give a result from their arguments alone.
+func (o *optimizer) pure(n node) bool {
+	switch n := n.(type) {
+	case *operatorNode:
+		if _, legacy := o.engine.operator(n.key); legacy || !o.builtIn(n.key) || n.key == "log" {
+			return false
+		}
+		for _, arg := range n.args {
+			if !o.pure(arg) {
+				return false
+			}
+		}
+	case *arrayNode:
+		for _, item := range n.items {
+			if !o.pure(item) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// nodeSize counts the nodes within n, including itself, stopping once there are more than max.
+func nodeSize(n node, max int) int {
+	var nodes []node
+	switch n := n.(type) {
+	case *operatorNode:
+		nodes = n.args
+	case *arrayNode:
+		nodes = n.items
+	}
+
+	size := 1
+	for _, item := range nodes {
+		if size > max {
+			break
+		}
+		size += nodeSize(item, max-size)
+	}
+	return size
+}
+
+// valueSize counts the values within value, including itself, stopping once there are more than max.
+func valueSize(value interface{}, max int) int {
+	size := 1
+	switch value := value.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if size > max {
+				break
+			}
+			size += valueSize(item, max-size)
+		}
+	case map[string]interface{}:
+		for _, item := range value {
+			if size > max {
+				break
+			}
+			size += valueSize(item, max-size)
+		}
+	}
+	return size
+}
+
+// valueNode returns a node which evaluates to the value, building arrays and objects afresh on each evaluation
+// as callers are free to modify the result.
+func valueNode(value interface{}, at *location) (node, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		items := make([]node, 0, len(value))
+		for i, item := range value {
+			n, ok := valueNode(item, at.argument(i))
+			if !ok {
+				return nil, false
+			}
+			items = append(items, n)
+		}
+		return &arrayNode{items: items, at: at}, true
+	case map[string]interface{}:
+		raw, err := json.Marshal(value)
+		return &objectNode{raw: raw}, err == nil
+	}
+	return &literalNode{value: value}, true
+}
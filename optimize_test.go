@@ -0,0 +1,170 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestOptimize(t *testing.T) {
+	rules := map[string]string{
+		`{"and":[true, {"var":"a"}]}`:                                       `{"var":"a"}`,
+		`{"and":[{"var":"a"}, {"and":[{"var":"b"}, {"var":"c"}]}]}`:         `{"and":[{"var":"a"},{"var":"b"},{"var":"c"}]}`,
+		`{"and":[{"var":"a"}, {"and":[]}]}`:                                 `{"and":[{"var":"a"},null]}`,
+		`{"or":[{"var":"a"}, 0, {"or":[{"var":"b"}, "yes", {"var":"c"}]}]}`: `{"or":[{"var":"a"},{"var":"b"},"yes"]}`,
+		`{"and":[{"var":"a"}, true]}`:                                       `{"and":[{"var":"a"},true]}`,
+		`{"!!":[{"!!":{"var":"a"}}]}`:                                       `{"!!":{"var":"a"}}`,
+		`{"cat":["a", {"cat":["b", 1]}, {"var":"c"}, {"cat":[]}]}`:          `{"cat":["ab1",{"var":"c"}]}`,
+		`{"cat":["a", {"cat":["b", 1]}]}`:                                   `"ab1"`,
+		`{"merge":[[1], {"merge":[[2], {"var":"a"}]}]}`:                     `{"merge":[[1],[2],{"var":"a"}]}`,
+		`{"<":[{"var":"a"}, {"*":[2, {"+":[3, 4]}]}]}`:                      `{"<":[{"var":"a"},14]}`,
+		`{"if":[false, {"var":"a"}, {"var":"b"}, {"var":"c"}, true, 1, 2]}`: `{"if":[{"var":"b"},{"var":"c"},1]}`,
+		`{"if":[{"==":[1, 1]}, {"var":"a"}, {"var":"b"}]}`:                  `{"var":"a"}`,
+		`{"if":[0, {"var":"a"}]}`:                                           `null`,
+		`{"map":[[1, 2], {"*":[{"var":""}, 2]}]}`:                           `[2,4]`,
+		`{"filter":[{"var":"a"}, {"<":[{"var":""}, {"-":[5, 1]}]}]}`:        `{"filter":[{"var":"a"},{"<":[{"var":""},4]}]}`,
+		`{"merge":[[{"a":1, "b":2}], {"substr":["abc", 1]}]}`:               `[{"a":1,"b":2},"bc"]`,
+	}
+
+	for rule, expected := range rules {
+		program, err := Compile(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		root := program.optimized
+		if root == nil {
+			root = program.root
+		}
+		optimized := &strings.Builder{}
+		encoder := json.NewEncoder(optimized)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(nodeRule(root))
+		if strings.TrimSpace(optimized.String()) != expected {
+			t.Fatalf("rule %s should optimize to %s, instead optimized to %s", rule, expected, optimized)
+		}
+	}
+}
+
+func TestOptimizePreservesResults(t *testing.T) {
+	data := []string{`{}`, `{"a":0, "b":"", "c":[1, 2]}`, `{"a":"x", "b":[], "c":null}`, `{"a":[3, 4, 5], "b":true, "c":"yes"}`}
+	rules := []string{
+		`{"and":[{"var":"a"}, {"and":[{"var":"b"}, {"var":"c"}]}, {"and":[]}]}`,
+		`{"or":[{"var":"a"}, {"or":[]}, {"or":[{"var":"b"}, 0]}, ""]}`,
+		`{"and":[1, {"or":[0, {"var":"b"}]}, "x"]}`,
+		`{"if":[{"var":"a"}, "a", false, "never", {"var":"b"}, "b"]}`,
+		`{"?:":[[], "empty", {"var":"c"}]}`,
+		`{"cat":[{"var":"a"}, {"cat":[1.5, null, true, [1, 2]]}, {"cat":[{"var":"c"}]}]}`,
+		`{"merge":[{"var":"a"}, {"merge":[[1, [2]], {"var":"c"}, {"merge":[]}]}]}`,
+		`{"!!":[{"!!":[{"var":"a"}]}]}`,
+		`{"map":[{"var":"a"}, {"+":[{"var":""}, {"/":[1, 0]}]}]}`,
+		`{"reduce":[[1, 2, 3], {"+":[{"var":"current"}, {"var":"accumulator"}]}, {"%":[7, 4]}]}`,
+		`{"all":[[1, 2], {">":[{"var":""}, 0]}]}`,
+		`{"in":[{"var":"c"}, {"merge":["yes", "no"]}]}`,
+		`[{"min":[3, 1]}, {"var":"a"}, {"<":[1, 2, 3]}]`,
+	}
+
+	for _, rule := range rules {
+		program, err := Compile(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if program.optimized == nil {
+			t.Fatalf("rule %s should be optimized", rule)
+		}
+
+		for _, d := range data {
+			ev := newEvaluation(context.Background(), program.engine, nil)
+			expected, expectedErr := program.root.eval(ev, jsonData(d))
+			result, err := program.optimized.eval(ev, jsonData(d))
+			if !reflect.DeepEqual(result, expected) || (err == nil) != (expectedErr == nil) {
+				t.Fatalf("rule %s should return %v, %v for %s, instead returned %v, %v", rule, expected, expectedErr, d, result, err)
+			}
+		}
+	}
+}
+
+func TestOptimizeKeepsErrors(t *testing.T) {
+	_, err := Run(`{"if":[true, {"cat":["a", {"substr":["b"]}]}]}`)
+
+	var arity *ArityError
+	if !errors.As(err, &arity) || arity.Path != "if[1].cat[1].substr" {
+		t.Fatalf("rule should return the arity error of substr, instead returned %v", err)
+	}
+}
+
+func TestOptimizeReplacedOperator(t *testing.T) {
+	engine := NewEngine()
+	program, err := engine.Compile(`{"and":[true, {"cat":["a", "b"]}]}`)
+	if err != nil || program.optimized == nil {
+		t.Fatalf("rule should compile and be optimized, instead returned %v", err)
+	}
+
+	engine.AddOperation("cat", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return "custom", nil
+	})
+
+	result, _ := program.Evaluate(`{}`)
+	if result != "custom" {
+		t.Fatalf("rule should use the custom operator added after compiling, instead returned %v", result)
+	}
+}
+
+func TestOptimizeFoldLimits(t *testing.T) {
+	// Each element doubles the accumulator, which would be far too large to fold
+	program, err := Compile(`{"reduce":[[1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30],
+		{"merge":[{"var":"accumulator"}, {"var":"accumulator"}]}, [1]]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if program.optimized != nil {
+		t.Fatal("rule should be left to be evaluated")
+	}
+}
+
+func TestOptimizeFoldNested(t *testing.T) {
+	// Each 'map' doubles the result of the one within it, so folding them all would never finish
+	depth := 3000
+	rule := strings.Repeat(`{"map":[[1, 2], `, depth) + `1` + strings.Repeat(`]}`, depth)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	program, err := Compile(rule)
+	runtime.ReadMemStats(&after)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 32<<20 {
+		t.Fatalf("folding should stop once the fold operations are used up, instead allocated %d bytes", allocated)
+	}
+
+	inner, err := Compile(`{"map":[[1, 2], {"map":[[1, 2], 1]}]}`)
+	if err != nil || inner.optimized == nil {
+		t.Fatalf("rule should still be folded, instead returned %v", err)
+	}
+	if program.optimized == nil {
+		t.Fatal("the innermost operators of the rule should still be folded")
+	}
+}
+
+func TestOptimizeCustomOperators(t *testing.T) {
+	engine := NewEngine()
+	calls := 0
+	engine.AddOperation("count", func(ctx *Context, args []interface{}) (interface{}, error) {
+		calls++
+		return calls, nil
+	})
+
+	program, _ := engine.Compile(`{"map":[[1, 2], {"count":[]}]}`)
+	program.Evaluate(`{}`)
+	program.Evaluate(`{}`)
+
+	if calls != 4 || program.optimized != nil {
+		t.Fatalf("custom operators shouldn't be folded, instead were called %d times", calls)
+	}
+}
@@ -0,0 +1,473 @@
+package jsonlogic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/buger/jsonparser"
+	"github.com/spf13/cast"
+)
+
+// PartialEvaluate simplifies a rule using the operators of the default engine, see Program.PartialEvaluate.
+func PartialEvaluate(rule string, knownData string) (string, error) {
+	return DefaultEngine.PartialEvaluate(rule, knownData)
+}
+
+// PartialEvaluate parses the rule and simplifies it using the operators of the engine, see Program.PartialEvaluate.
+func (e *Engine) PartialEvaluate(rule string, knownData string) (string, error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return "", err
+	}
+
+	return program.PartialEvaluate(knownData)
+}
+
+// PartialEvaluate evaluates as much of the rule as can be decided from the data known so far, returning the rule
+// which remains as json to be evaluated once the rest of the data is known.
+// A 'var' whose key is within the known data is replaced by its value and any operator whose arguments are then all
+// known is replaced by its result, so 'and' with a clause known to be falsy becomes that value and 'if' with a condition
+// known to be truthy becomes the branch taken. Custom operators and 'log' are always left to the remaining rule, as are
+// array operations running them against each element. A 'var' whose value can't be written within a rule, such as
+// an object with a single key, is left to the remaining rule too.
+func (p *Program) PartialEvaluate(knownData string) (string, error) {
+	known, err := parseData(knownData)
+	if err != nil {
+		return "", err
+	}
+
+	pe := &partialEvaluation{ev: newEvaluation(context.Background(), p.engine, nil), known: known}
+	result, err := pe.node(p.root, "")
+	if err != nil {
+		return "", err
+	}
+
+	rule, err := result.toRule()
+	if err != nil {
+		return "", err
+	}
+
+	b := &bytes.Buffer{}
+	encoder := json.NewEncoder(b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(rule); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n"))), nil
+}
+
+// partial is the result of partially evaluating a node, either a value when it is known or a rule left to evaluate.
+type partial struct {
+	known bool
+	value interface{}
+	rule  interface{}
+	path  string
+}
+
+// toRule returns the json of the rule for the node, which for a known value is the value itself.
+func (p partial) toRule() (interface{}, error) {
+	if !p.known {
+		return p.rule, nil
+	}
+	if !isLiteral(p.value) {
+		return nil, fmt.Errorf("value %s at %s can't be written within a rule", traceValue(p.value), p.path)
+	}
+	return p.value, nil
+}
+
+// isLiteral reports whether a value evaluates to itself when written within a rule, which isn't the case for an object
+// with a single key as that is an operator.
+func isLiteral(value interface{}) bool {
+	switch value := value.(type) {
+	case float64:
+		return !math.IsNaN(value) && !math.IsInf(value, 0)
+	case []interface{}:
+		for _, item := range value {
+			if !isLiteral(item) {
+				return false
+			}
+		}
+	case map[string]interface{}:
+		return len(value) != 1
+	}
+	return true
+}
+
+// partialEvaluation holds the state of a single partial evaluation of a program.
+type partialEvaluation struct {
+	ev    *evaluation
+	known jsonData
+}
+
+func (pe *partialEvaluation) node(n node, path string) (partial, error) {
+	switch n := n.(type) {
+	case *operatorNode:
+		return pe.operator(n)
+	case *arrayNode:
+		items, err := pe.nodes(n.items, path)
+		if err != nil {
+			return partial{}, err
+		}
+
+		values := make([]interface{}, 0, len(items))
+		for _, item := range items {
+			if !item.known {
+				return residual(path, "", items)
+			}
+			values = append(values, item.value)
+		}
+		return partial{known: true, value: values, path: path}, nil
+	}
+
+	value, err := n.eval(pe.ev, pe.known)
+	return partial{known: true, value: value, path: path}, err
+}
+
+// nodes partially evaluates each of the arguments of an operator or elements of an array found at path.
+func (pe *partialEvaluation) nodes(nodes []node, path string) ([]partial, error) {
+	results := make([]partial, 0, len(nodes))
+	for i, n := range nodes {
+		result, err := pe.node(n, argumentPath(path, i))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (pe *partialEvaluation) operator(n *operatorNode) (partial, error) {
+	// Custom operators may depend on more than their arguments, those registered with AddOperator parse their own
+	if _, ok := pe.ev.engine.operator(n.key); ok {
+		var args interface{}
+		err := json.Unmarshal(n.rule, &args)
+		return partial{rule: map[string]interface{}{n.key: args}, path: n.path()}, err
+	}
+	_, custom := pe.ev.engine.operation(n.key)
+	arity, builtIn := operatorArity[n.key]
+	if custom || !builtIn || n.key == "log" {
+		return pe.residual(n)
+	}
+
+	if len(n.args) < arity.min || (arity.max >= 0 && len(n.args) > arity.max) {
+		return partial{}, &ArityError{Operator: n.key, Path: n.path(), Min: arity.min, Max: arity.max, Got: len(n.args)}
+	}
+
+	switch n.key {
+	case "var":
+		return pe.variable(n)
+	case "missing":
+		return pe.missing(n)
+	case "missing_some":
+		return pe.missingSome(n)
+	case "and", "or":
+		return pe.logic(n)
+	case "if", "?:":
+		return pe.branches(n)
+	case "all", "some", "none", "map", "filter", "reduce":
+		return pe.scoped(n)
+	}
+
+	args, err := pe.nodes(n.args, n.path())
+	if err != nil {
+		return partial{}, err
+	}
+	for _, arg := range args {
+		if !arg.known {
+			return residual(n.path(), n.key, args)
+		}
+	}
+	return pe.fold(n, literals(args...))
+}
+
+// residual partially evaluates the arguments of an operator which itself is left to the remaining rule.
+func (pe *partialEvaluation) residual(n *operatorNode) (partial, error) {
+	args, err := pe.nodes(n.args, n.path())
+	if err != nil {
+		return partial{}, err
+	}
+	return residual(n.path(), n.key, args)
+}
+
+// fold runs a built in operator against its arguments, which are known other than rules run against each element of an array.
+func (pe *partialEvaluation) fold(n *operatorNode, args []node) (partial, error) {
+	result, err := runOperator(pe.ev, n.key, args, pe.known)
+	if err == nil {
+		err = pe.ev.checkSize(result, n.at)
+	}
+	return partial{known: true, value: result, path: n.path()}, withPath(err, n.key, n.at)
+}
+
+// literals returns nodes for the values of known arguments.
+func literals(args ...partial) []node {
+	nodes := make([]node, 0, len(args))
+	for _, arg := range args {
+		nodes = append(nodes, &literalNode{value: arg.value})
+	}
+	return nodes
+}
+
+// variable replaces a 'var' with its value when the key is within the known data.
+func (pe *partialEvaluation) variable(n *operatorNode) (partial, error) {
+	args, err := pe.nodes(n.args, n.path())
+	if err != nil {
+		return partial{}, err
+	}
+	if len(args) == 0 || !args[0].known || isEmptyKey(args[0].value) {
+		return residual(n.path(), n.key, args)
+	}
+
+	_, dataType := jsonGet(pe.known, args[0].value)
+	switch dataType {
+	case jsonparser.NotExist:
+		return residual(n.path(), n.key, args)
+	case jsonparser.Null:
+		if len(args) > 1 {
+			return args[1], nil
+		}
+	}
+
+	result, err := pe.fold(n, literals(args[0]))
+	if err == nil && !isLiteral(result.value) {
+		return residual(n.path(), n.key, args)
+	}
+	return result, err
+}
+
+// missing leaves only the keys which aren't known to be present to a 'missing' which isn't yet known.
+func (pe *partialEvaluation) missing(n *operatorNode) (partial, error) {
+	args, err := pe.nodes(n.args, n.path())
+	if err != nil {
+		return partial{}, err
+	}
+
+	values := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		if !arg.known {
+			return residual(n.path(), n.key, args)
+		}
+		values = append(values, arg.value)
+	}
+	if len(values) > 0 {
+		if keys, ok := values[0].([]interface{}); ok {
+			values = keys
+		}
+	}
+
+	keys, _, unknown := pe.missingKeys(values)
+	if !unknown {
+		return partial{known: true, value: keys, path: n.path()}, nil
+	}
+	return partial{rule: map[string]interface{}{n.key: keys}, path: n.path()}, nil
+}
+
+// missingSome leaves only the keys which aren't known to be present to a 'missing_some' which isn't yet known,
+// needing as many fewer of them as are.
+func (pe *partialEvaluation) missingSome(n *operatorNode) (partial, error) {
+	args, err := pe.nodes(n.args, n.path())
+	if err != nil {
+		return partial{}, err
+	}
+	if !args[0].known || !args[1].known {
+		return residual(n.path(), n.key, args)
+	}
+
+	values, ok := args[1].value.([]interface{})
+	if !ok {
+		return pe.fold(n, literals(args...))
+	}
+
+	need := cast.ToInt(args[0].value)
+	keys, present, unknown := pe.missingKeys(values)
+	switch {
+	case present >= need:
+		return partial{known: true, value: make([]interface{}, 0), path: n.path()}, nil
+	case !unknown:
+		return partial{known: true, value: keys, path: n.path()}, nil
+	}
+	return partial{rule: map[string]interface{}{n.key: []interface{}{float64(need - present), keys}}, path: n.path()}, nil
+}
+
+// missingKeys returns the keys which are either known to be missing or not yet known, along with the number of
+// keys known to be present and whether any aren't yet known.
+func (pe *partialEvaluation) missingKeys(values []interface{}) ([]interface{}, int, bool) {
+	keys := make([]interface{}, 0, len(values))
+	unknown := false
+	for _, key := range values {
+		if _, dataType := jsonGet(pe.known, key); dataType == jsonparser.NotExist {
+			unknown = true
+		} else if !isMissing(key, pe.known) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, len(values) - len(keys), unknown
+}
+
+// logic simplifies 'and' and 'or', which become the first known value deciding them when every value before it is
+// known, otherwise end at it. Known values which don't decide them are dropped, other than the last as that is the
+// result when no other value decides them.
+func (pe *partialEvaluation) logic(n *operatorNode) (partial, error) {
+	decides := truthy
+	if n.key == "and" {
+		decides = func(value interface{}) bool { return !truthy(value) }
+	}
+
+	args := make([]partial, 0, len(n.args))
+	for i, arg := range n.args {
+		result, err := pe.node(arg, argumentPath(n.path(), i))
+		if err != nil {
+			return partial{}, err
+		}
+
+		switch {
+		case result.known && decides(result.value) && len(args) == 0:
+			return result, nil
+		case result.known && decides(result.value):
+			// The operands before it which aren't yet known may still decide it, but none after it can
+			return residual(n.path(), n.key, append(args, result))
+		case result.known && i < len(n.args)-1:
+			continue
+		}
+		args = append(args, result)
+	}
+
+	switch len(args) {
+	case 0:
+		return pe.fold(n, nil)
+	case 1:
+		return args[0], nil
+	}
+	return residual(n.path(), n.key, args)
+}
+
+// branches simplifies 'if', dropping branches whose condition is known to be falsy and ending at the first
+// whose condition is known to be truthy, the branch of which becomes the final unpaired argument.
+func (pe *partialEvaluation) branches(n *operatorNode) (partial, error) {
+	args := make([]partial, 0, len(n.args))
+	i := 0
+	for ; i+1 < len(n.args); i += 2 {
+		condition, err := pe.node(n.args[i], argumentPath(n.path(), i))
+		if err != nil {
+			return partial{}, err
+		}
+		if condition.known && !truthy(condition.value) {
+			continue
+		}
+
+		branch, err := pe.node(n.args[i+1], argumentPath(n.path(), i+1))
+		if err != nil {
+			return partial{}, err
+		}
+		if condition.known {
+			args = append(args, branch)
+			break
+		}
+		args = append(args, condition, branch)
+	}
+
+	if i+1 == len(n.args) {
+		otherwise, err := pe.node(n.args[i], argumentPath(n.path(), i))
+		if err != nil {
+			return partial{}, err
+		}
+		args = append(args, otherwise)
+	}
+
+	switch len(args) {
+	case 0:
+		return partial{known: true, value: nil, path: n.path()}, nil
+	case 1:
+		return args[0], nil
+	}
+	return residual(n.path(), n.key, args)
+}
+
+// scoped runs array operations once the array is known and the rule run against each element only uses built in
+// operators which give a result from their arguments alone, otherwise leaves them with that rule unchanged as the data
+// it sees is only known once the array is.
+func (pe *partialEvaluation) scoped(n *operatorNode) (partial, error) {
+	args := make([]partial, 0, len(n.args))
+	known := true
+	for i, arg := range n.args {
+		if i == 1 {
+			args = append(args, partial{rule: nodeRule(arg), path: argumentPath(n.path(), i)})
+			continue
+		}
+
+		result, err := pe.node(arg, argumentPath(n.path(), i))
+		if err != nil {
+			return partial{}, err
+		}
+		args = append(args, result)
+		known = known && result.known
+	}
+
+	if !known || (len(n.args) > 1 && !(&optimizer{engine: pe.ev.engine}).pure(n.args[1])) {
+		return residual(n.path(), n.key, args)
+	}
+
+	nodes := literals(args...)
+	nodes[1] = n.args[1]
+	return pe.fold(n, nodes)
+}
+
+// residual returns the rule of an operator with the given arguments, or of an array of them when there's no key.
+func residual(path string, key string, args []partial) (partial, error) {
+	rules := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		rule, err := arg.toRule()
+		if err != nil {
+			return partial{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if key == "" {
+		return partial{rule: rules, path: path}, nil
+	}
+	return partial{rule: operatorRule(key, rules), path: path}, nil
+}
+
+// operatorRule returns the json of an operator, without wrapping a single argument in an array where it isn't needed.
+func operatorRule(key string, args []interface{}) map[string]interface{} {
+	if len(args) == 1 {
+		if _, ok := args[0].([]interface{}); !ok {
+			return map[string]interface{}{key: args[0]}
+		}
+	}
+	return map[string]interface{}{key: args}
+}
+
+// nodeRule returns the json of a compiled node.
+func nodeRule(n node) interface{} {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value
+	case *arrayNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			items = append(items, nodeRule(item))
+		}
+		return items
+	case *objectNode:
+		var value map[string]interface{}
+		json.Unmarshal(n.raw, &value)
+		return value
+	case *operatorNode:
+		args := make([]interface{}, 0, len(n.args))
+		for _, arg := range n.args {
+			args = append(args, nodeRule(arg))
+		}
+		return operatorRule(n.key, args)
+	}
+	return nil
+}
+
+// isEmptyKey reports whether a var key is for the whole of the data, which is never entirely known.
+func isEmptyKey(key interface{}) bool {
+	return cast.ToString(key) == ""
+}
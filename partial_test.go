@@ -0,0 +1,155 @@
+package jsonlogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartialEvaluate(t *testing.T) {
+	known := `{"customer":{"tier":"gold", "age":30, "nickname":null}, "country":"GB"}`
+	rules := map[string]string{
+		`{"and":[{"==":[{"var":"customer.tier"}, "silver"]}, {">":[{"var":"cart.total"}, 100]}]}`:                 `false`,
+		`{"and":[{"==":[{"var":"customer.tier"}, "gold"]}, {">":[{"var":"cart.total"}, 100]}]}`:                   `{">":[{"var":"cart.total"},100]}`,
+		`{"or":[{"var":"cart.coupon"}, {"<":[{"var":"customer.age"}, 18]}, {"var":"cart.staff"}]}`:                `{"or":[{"var":"cart.coupon"},{"var":"cart.staff"}]}`,
+		`{"or":[{"var":"cart.coupon"}, {"<":[{"var":"customer.age"}, 18]}]}`:                                      `{"or":[{"var":"cart.coupon"},false]}`,
+		`{"or":[{"var":"cart.coupon"}, {"var":"country"}, {"var":"cart.staff"}]}`:                                 `{"or":[{"var":"cart.coupon"},"GB"]}`,
+		`{"if":[{"==":[{"var":"country"}, "US"]}, {"*":[{"var":"cart.total"}, 0.1]}, 0]}`:                         `0`,
+		`{"if":[{"var":"cart.gift"}, 0, {"==":[{"var":"country"}, "GB"]}, {"*":[{"var":"cart.total"}, 0.2]}, 1]}`: `{"if":[{"var":"cart.gift"},0,{"*":[{"var":"cart.total"},0.2]}]}`,
+		`{"if":[{"==":[{"var":"country"}, "US"]}, 1]}`:                                                            `null`,
+		`{"cat":[{"var":"customer.tier"}, "-", {"var":["customer.nickname", "none"]}]}`:                           `"gold-none"`,
+		`{"var":["cart.currency", {"var":"country"}]}`:                                                            `{"var":["cart.currency","GB"]}`,
+		`{"missing":["customer.tier", "customer.nickname", "cart.total"]}`:                                        `{"missing":["customer.nickname","cart.total"]}`,
+		`{"missing":["customer.tier", "country"]}`:                                                                `[]`,
+		`{"map":[{"var":"cart.items"}, {"*":[{"var":"price"}, 2]}]}`:                                              `{"map":[{"var":"cart.items"},{"*":[{"var":"price"},2]}]}`,
+		`{"reduce":[[1, 2], {"+":[{"var":"current"}, {"var":"accumulator"}]}, {"var":"customer.age"}]}`:           `33`,
+		`[{"var":"country"}, {"var":"cart.total"}]`:                                                               `["GB",{"var":"cart.total"}]`,
+		`{"log":{"var":"country"}}`:                                                                               `{"log":"GB"}`,
+		`{"in":["<", {"var":"cart.note"}]}`:                                                                       `{"in":["<",{"var":"cart.note"}]}`,
+	}
+
+	for rule, expected := range rules {
+		residual, err := PartialEvaluate(rule, known)
+		if err != nil || residual != expected {
+			t.Fatalf("rule %s should simplify to %s, instead returned %s, %v", rule, expected, residual, err)
+		}
+	}
+}
+
+func TestPartialEvaluateMatchesApply(t *testing.T) {
+	known := `{"customer":{"tier":"gold", "age":30}}`
+	data := `{"customer":{"tier":"gold", "age":30}, "cart":{"total":120, "discount":0, "items":[{"price":1}, {"price":2}]}}`
+	rules := []string{
+		`{"and":[{"==":[{"var":"customer.tier"}, "gold"]}, {">":[{"var":"cart.total"}, 100]}]}`,
+		`{"if":[{"<":[{"var":"cart.total"}, 50]}, "small", {">":[{"var":"customer.age"}, 18]}, "adult", "other"]}`,
+		`{"map":[{"var":"cart.items"}, {"*":[{"var":"price"}, {"var":""}]}]}`,
+		`{"+":[{"var":"customer.age"}, {"var":"cart.total"}, {"max":[1, 2, 3]}]}`,
+		`{"!":[{"missing_some":[1, ["customer.tier", "cart.coupon"]]}]}`,
+		`{"missing_some":[2, ["customer.tier", "cart.coupon", "cart.total"]]}`,
+		`{"or":[{"var":"cart.total"}, {"==":[{"var":"customer.tier"}, "gold"]}, {"var":"cart.coupon"}]}`,
+		`{"or":[{"var":"cart.coupon"}, 0, {"==":[{"var":"customer.tier"}, "gold"]}]}`,
+		`{"and":[{"var":"cart.discount"}, {"==":[{"var":"customer.tier"}, "silver"]}, {"var":"cart.total"}]}`,
+		`{"and":[{"var":"cart.total"}, true, {"<":[{"var":"customer.age"}, 18]}]}`,
+		`{"missing_some":[3, ["customer.tier", "cart.coupon", "cart.total"]]}`,
+	}
+
+	for _, rule := range rules {
+		residual, err := PartialEvaluate(rule, known)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, _ := Apply(rule, data)
+		result, err := Apply(residual, data)
+		if err != nil || !reflect.DeepEqual(result, expected) {
+			t.Fatalf("residual %s of rule %s should return %v, instead returned %v, %v", residual, rule, expected, result, err)
+		}
+	}
+}
+
+func TestPartialEvaluateMissingSome(t *testing.T) {
+	known := `{"customer":{"name":"Bruce", "email":""}}`
+	rules := map[string]string{
+		`{"missing_some":[1, ["customer.name", "cart.id"]]}`:                   `[]`,
+		`{"missing_some":[2, ["customer.name", "cart.id", "cart.total"]]}`:     `{"missing_some":[1,["cart.id","cart.total"]]}`,
+		`{"missing_some":[1, ["customer.email", "cart.id"]]}`:                  `{"missing_some":[1,["customer.email","cart.id"]]}`,
+		`{"missing_some":[2, ["customer.name", "customer.email"]]}`:            `["customer.email"]`,
+		`{"missing_some":[1, ["customer.phone", "cart.id"]]}`:                  `{"missing_some":[1,["customer.phone","cart.id"]]}`,
+		`{"missing_some":[{"var":"cart.need"}, ["customer.name", "cart.id"]]}`: `{"missing_some":[{"var":"cart.need"},["customer.name","cart.id"]]}`,
+	}
+
+	for rule, expected := range rules {
+		residual, err := PartialEvaluate(rule, known)
+		if err != nil || residual != expected {
+			t.Fatalf("rule %s should simplify to %s, instead returned %s, %v", rule, expected, residual, err)
+		}
+	}
+
+	residual, err := PartialEvaluate(`{"missing_some":[1, ["customer.name", "cart.id"]]}`, `{"customer":{}}`)
+	if err != nil || residual != `{"missing_some":[1,["customer.name","cart.id"]]}` {
+		t.Fatalf("keys which aren't known should be left, instead returned %s, %v", residual, err)
+	}
+}
+
+func TestPartialEvaluateUnwritableValue(t *testing.T) {
+	rules := map[string]string{
+		`{"==":[{"var":"customer"}, {"var":"cart.x"}]}`: `{"==":[{"var":"customer"},{"var":"cart.x"}]}`,
+		`{"var":["customer", "nobody"]}`:                `{"var":["customer","nobody"]}`,
+		`{"var":"customer.tier"}`:                       `"gold"`,
+	}
+
+	for rule, expected := range rules {
+		residual, err := PartialEvaluate(rule, `{"customer":{"tier":"gold"}}`)
+		if err != nil || residual != expected {
+			t.Fatalf("rule %s should simplify to %s, instead returned %s, %v", rule, expected, residual, err)
+		}
+	}
+}
+
+func TestPartialEvaluateCustomOperators(t *testing.T) {
+	engine := NewEngine()
+	calls := 0
+	engine.AddOperation("double", func(ctx *Context, args []interface{}) (interface{}, error) {
+		calls++
+		return args[0].(float64) * 2, nil
+	})
+	engine.AddOperator("legacy", func(rule string, data string) interface{} {
+		return true
+	})
+
+	rules := map[string]string{
+		`{"double":{"var":"a"}}`:                  `{"double":1}`,
+		`{"legacy":[{"var":"a"}, {"var":"b"}]}`:   `{"legacy":[{"var":"a"},{"var":"b"}]}`,
+		`{"map":[[1, 2], {"double":{"var":""}}]}`: `{"map":[[1,2],{"double":{"var":""}}]}`,
+		`{"all":[[1], {"log":{"var":""}}]}`:       `{"all":[[1],{"log":{"var":""}}]}`,
+		`{"map":[[1, 2], {"+":[{"var":""}, 1]}]}`: `[2,3]`,
+	}
+
+	for rule, expected := range rules {
+		residual, err := engine.PartialEvaluate(rule, `{"a":1}`)
+		if err != nil || residual != expected {
+			t.Fatalf("rule %s should simplify to %s, instead returned %s, %v", rule, expected, residual, err)
+		}
+	}
+
+	// Custom operations are never run, including against the elements of a known array
+	if calls != 0 {
+		t.Fatalf("custom operations should be left to the remaining rule, instead called %d times", calls)
+	}
+}
+
+func TestPartialEvaluateErrors(t *testing.T) {
+	rules := []string{
+		`{"substr":[{"var":"b"}]}`,
+		`{"and":[}`,
+	}
+
+	for _, rule := range rules {
+		if residual, err := PartialEvaluate(rule, `{"a":{"b":1}}`); err == nil {
+			t.Fatalf("rule %s should fail, instead returned %s", rule, residual)
+		}
+	}
+
+	if _, err := PartialEvaluate(`{"var":"a"}`, `{`); err == nil {
+		t.Fatal("invalid known data should fail")
+	}
+}
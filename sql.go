@@ -0,0 +1,455 @@
+package jsonlogic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect is a flavour of SQL which ToSQL writes.
+type Dialect int
+
+const (
+	// PostgreSQL writes placeholders as $1, $2 and so on.
+	PostgreSQL Dialect = iota + 1
+	// SQLite writes placeholders as ?1, ?2 and so on.
+	SQLite
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case PostgreSQL:
+		return "PostgreSQL"
+	case SQLite:
+		return "SQLite"
+	}
+	return fmt.Sprintf("Dialect(%d)", int(d))
+}
+
+// placeholder is the placeholder of the nth bind argument, counting from 1.
+// Both are numbered so an argument can be referred to more than once.
+func (d Dialect) placeholder(n int) string {
+	if d == SQLite {
+		return fmt.Sprintf("?%d", n)
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// boolean is a boolean literal, SQLite only having keywords for them since 3.23.
+func (d Dialect) boolean(value bool) string {
+	switch {
+	case d == SQLite && value:
+		return "1"
+	case d == SQLite:
+		return "0"
+	case value:
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// float is the type numbers are cast to so division isn't of integers.
+func (d Dialect) float() string {
+	if d == SQLite {
+		return "REAL"
+	}
+	return "DOUBLE PRECISION"
+}
+
+// ColumnMapper returns the SQL expression of the column for the path of a 'var', such as 'pie.filling', or an error
+// if there isn't one. The expression is written into the SQL as it is, so it must never come from the rule itself.
+type ColumnMapper func(path string) (string, error)
+
+// Columns returns a ColumnMapper for a fixed set of paths, any other path being an error.
+func Columns(columns map[string]string) ColumnMapper {
+	return func(path string) (string, error) {
+		column, ok := columns[path]
+		if !ok {
+			return "", fmt.Errorf("no column for %q", path)
+		}
+		return column, nil
+	}
+}
+
+// ToSQL translates a rule into an SQL predicate for a WHERE clause, using the operators of the default engine,
+// see Engine.ToSQL.
+func ToSQL(rule string, dialect Dialect, columns ColumnMapper) (string, []interface{}, error) {
+	return DefaultEngine.ToSQL(rule, dialect, columns)
+}
+
+// ToSQL translates a rule into an SQL predicate for a WHERE clause along with its bind arguments, where each literal
+// of the rule is a bind argument and each 'var' the column given by columns for its path.
+// Comparisons, 'and', 'or', '!', 'if', 'in', 'missing', 'missing_some', arithmetic and 'cat' are translated, where they
+// follow the types and comparisons of the database rather than the coercion of JsonLogic. Any other operator, including
+// custom operators, returns a *TranslationError, as does a 'var' whose column can't be found.
+func (e *Engine) ToSQL(rule string, dialect Dialect, columns ColumnMapper) (string, []interface{}, error) {
+	if dialect != PostgreSQL && dialect != SQLite {
+		return "", nil, fmt.Errorf("unknown SQL dialect %s", dialect)
+	}
+
+	program, err := e.Compile(rule)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := &sqlBuilder{engine: e, dialect: dialect, columns: columns, args: make([]interface{}, 0)}
+	sql, err := b.expr(program.root, "")
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, b.args, nil
+}
+
+// sqlBuilder writes the SQL of a compiled rule, collecting its bind arguments.
+type sqlBuilder struct {
+	engine  *Engine
+	dialect Dialect
+	columns ColumnMapper
+	args    []interface{}
+}
+
+// bind adds a bind argument, returning its placeholder.
+func (b *sqlBuilder) bind(value interface{}) string {
+	b.args = append(b.args, value)
+	return b.dialect.placeholder(len(b.args))
+}
+
+// untranslatable returns the error for an operator, or a value when the key is empty, which can't be translated.
+func (b *sqlBuilder) untranslatable(key string, path string, reason string) error {
+	return &TranslationError{Target: "SQL", Operator: key, Path: path, Reason: reason}
+}
+
+func (b *sqlBuilder) expr(n node, path string) (string, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		switch value := n.value.(type) {
+		case nil:
+			return "NULL", nil
+		case bool:
+			return b.dialect.boolean(value), nil
+		}
+		return b.bind(n.value), nil
+	case *arrayNode:
+		return "", b.untranslatable("", path, "arrays are only translated as the values of 'in' and keys of 'missing'")
+	case *objectNode:
+		return "", b.untranslatable("", path, "objects have no translation")
+	case *operatorNode:
+		return b.operator(n)
+	}
+	return "", b.untranslatable("", path, "unknown value")
+}
+
+// exprs translates the arguments of an operator.
+func (b *sqlBuilder) exprs(n *operatorNode) ([]string, error) {
+	exprs := make([]string, 0, len(n.args))
+	for i, arg := range n.args {
+		expr, err := b.expr(arg, argumentPath(n.path(), i))
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+func (b *sqlBuilder) operator(n *operatorNode) (string, error) {
+	_, legacy := b.engine.operator(n.key)
+	_, custom := b.engine.operation(n.key)
+	if legacy || custom {
+		return "", b.untranslatable(n.key, n.path(), "custom operators have no translation")
+	}
+
+	arity, ok := operatorArity[n.key]
+	if !ok {
+		return "", &UnknownOperatorError{Operator: n.key, Path: n.path()}
+	}
+	if len(n.args) < arity.min || (arity.max >= 0 && len(n.args) > arity.max) {
+		return "", &ArityError{Operator: n.key, Path: n.path(), Min: arity.min, Max: arity.max, Got: len(n.args)}
+	}
+
+	switch {
+	case !sqlOperators[n.key]:
+		return "", b.untranslatable(n.key, n.path(), "it has no translation")
+	case (n.key == "!" || n.key == "!!") && len(n.args) == 0:
+		return "", b.untranslatable(n.key, n.path(), "it has no argument")
+	}
+
+	switch n.key {
+	case "var":
+		return b.column(n)
+	case "missing", "missing_some":
+		return b.missing(n)
+	case "in":
+		return b.in(n)
+	}
+
+	args, err := b.exprs(n)
+	if err != nil {
+		return "", err
+	}
+	if numericOperators[n.key] {
+		args = b.numeric(n, args)
+	}
+
+	switch n.key {
+	case "==", "===":
+		return b.equal(n, args, "=", "IS NULL"), nil
+	case "!=", "!==":
+		return b.equal(n, args, "<>", "IS NOT NULL"), nil
+	case ">", ">=":
+		return fmt.Sprintf("(%s %s %s)", args[0], n.key, args[1]), nil
+	case "<", "<=":
+		if len(args) > 2 {
+			return fmt.Sprintf("(%s %s %s AND %s %s %s)", args[0], n.key, args[1], args[1], n.key, args[2]), nil
+		}
+		return fmt.Sprintf("(%s %s %s)", args[0], n.key, args[1]), nil
+	case "and", "or":
+		if len(args) == 0 {
+			return b.dialect.boolean(false), nil
+		}
+		return join(args, " "+strings.ToUpper(n.key)+" "), nil
+	case "!":
+		return fmt.Sprintf("(NOT %s)", args[0]), nil
+	case "!!":
+		return args[0], nil
+	case "if", "?:":
+		return b.conditional(args), nil
+	case "+", "*":
+		return b.arithmetic(n.key, args), nil
+	case "-":
+		if len(args) == 1 {
+			return fmt.Sprintf("(-%s)", args[0]), nil
+		}
+		return join(args, " - "), nil
+	case "/":
+		return fmt.Sprintf("(CAST(%s AS %s) / %s)", args[0], b.dialect.float(), args[1]), nil
+	case "%":
+		return fmt.Sprintf("(%s %% %s)", args[0], args[1]), nil
+	case "max", "min":
+		return b.extreme(n.key, args), nil
+	case "cat":
+		if len(args) == 0 {
+			return b.bind(""), nil
+		}
+		for i, arg := range args {
+			args[i] = fmt.Sprintf("COALESCE(CAST(%s AS TEXT), '')", arg)
+		}
+		return join(args, " || "), nil
+	}
+	return "", b.untranslatable(n.key, n.path(), "it has no translation")
+}
+
+// sqlOperators are the built in operators which ToSQL translates.
+var sqlOperators = map[string]bool{
+	"var": true, "missing": true, "missing_some": true,
+	"==": true, "===": true, "!=": true, "!==": true, ">": true, ">=": true, "<": true, "<=": true,
+	"and": true, "or": true, "!": true, "!!": true, "if": true, "?:": true, "in": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true, "max": true, "min": true, "cat": true,
+}
+
+// numericOperators are the operators whose arguments are numbers.
+var numericOperators = map[string]bool{"+": true, "-": true, "*": true, "%": true, "max": true, "min": true}
+
+// numeric casts the bind arguments of an operator on numbers to NUMERIC in PostgreSQL, which can't choose an
+// operator for two arguments of unknown type, and every argument of '%', which it doesn't define for floating point.
+func (b *sqlBuilder) numeric(n *operatorNode, args []string) []string {
+	if b.dialect != PostgreSQL {
+		return args
+	}
+	for i, arg := range args {
+		if n.key == "%" || isBound(n.args[i]) {
+			args[i] = fmt.Sprintf("CAST(%s AS NUMERIC)", arg)
+		}
+	}
+	return args
+}
+
+// isBound reports whether a node is a literal written as a bind argument or NULL, whose type isn't known.
+func isBound(n node) bool {
+	value, ok := n.(*literalNode)
+	if !ok {
+		return false
+	}
+	_, boolean := value.value.(bool)
+	return !boolean
+}
+
+// column translates a 'var' into its column, along with its fallback when it has one.
+func (b *sqlBuilder) column(n *operatorNode) (string, error) {
+	if len(n.args) == 0 {
+		return "", b.untranslatable(n.key, n.path(), "all of the data isn't a column")
+	}
+	key, ok := n.args[0].(*literalNode)
+	if !ok {
+		return "", b.untranslatable(n.key, n.path(), "the key is computed")
+	}
+	path := varPath(key.value)
+	if path == "" {
+		return "", b.untranslatable(n.key, n.path(), "all of the data isn't a column")
+	}
+
+	column, err := b.columns(path)
+	if err != nil {
+		return "", &TranslationError{Target: "SQL", Operator: n.key, Path: n.path(), Reason: err.Error(), Err: err}
+	}
+	if len(n.args) == 1 {
+		return column, nil
+	}
+
+	fallback, err := b.expr(n.args[1], argumentPath(n.path(), 1))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("COALESCE(%s, %s)", column, fallback), nil
+}
+
+// missing translates 'missing' into whether any of the keys are null or empty, and 'missing_some' into whether
+// fewer than the number needed aren't, as that is when their result is truthy.
+func (b *sqlBuilder) missing(n *operatorNode) (string, error) {
+	args := n.args
+	if n.key == "missing_some" {
+		args = n.args[1:]
+	}
+	keys, ok := missingKeyArgs(args)
+	if !ok {
+		return "", b.untranslatable(n.key, n.path(), "the keys are computed")
+	}
+
+	conditions := make([]string, 0, len(keys))
+	for _, key := range keys {
+		column, err := b.columns(varPath(key))
+		if err != nil {
+			return "", &TranslationError{Target: "SQL", Operator: n.key, Path: n.path(), Reason: err.Error(), Err: err}
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s IS NULL OR CAST(%s AS TEXT) = '')", column, column))
+	}
+
+	if n.key == "missing" {
+		if len(conditions) == 0 {
+			return b.dialect.boolean(false), nil
+		}
+		return join(conditions, " OR "), nil
+	}
+
+	need, err := b.expr(n.args[0], argumentPath(n.path(), 0))
+	if err != nil {
+		return "", err
+	}
+	present := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		present = append(present, fmt.Sprintf("CASE WHEN %s THEN 0 ELSE 1 END", condition))
+	}
+	if len(present) == 0 {
+		present = append(present, "0")
+	}
+	return fmt.Sprintf("(%s < %s)", join(present, " + "), need), nil
+}
+
+// equal translates an equality, comparing to null with IS NULL or IS NOT NULL as nothing is equal to null in SQL.
+func (b *sqlBuilder) equal(n *operatorNode, args []string, operator string, null string) string {
+	switch {
+	case isNull(n.args[1]):
+		return fmt.Sprintf("(%s %s)", args[0], null)
+	case isNull(n.args[0]):
+		return fmt.Sprintf("(%s %s)", args[1], null)
+	}
+	return fmt.Sprintf("(%s %s %s)", args[0], operator, args[1])
+}
+
+// conditional translates 'if' into a CASE of each condition and branch, or NULL when it has none.
+func (b *sqlBuilder) conditional(args []string) string {
+	switch len(args) {
+	case 0:
+		return "NULL"
+	case 1:
+		return args[0]
+	}
+
+	sql := &strings.Builder{}
+	sql.WriteString("(CASE")
+	i := 0
+	for ; i+1 < len(args); i += 2 {
+		fmt.Fprintf(sql, " WHEN %s THEN %s", args[i], args[i+1])
+	}
+	if i < len(args) {
+		fmt.Fprintf(sql, " ELSE %s", args[i])
+	}
+	sql.WriteString(" END)")
+	return sql.String()
+}
+
+// in translates 'in' of an array into IN, and of anything else into whether it contains the string.
+func (b *sqlBuilder) in(n *operatorNode) (string, error) {
+	value, err := b.expr(n.args[0], argumentPath(n.path(), 0))
+	if err != nil {
+		return "", err
+	}
+
+	array, ok := n.args[1].(*arrayNode)
+	if !ok {
+		text, err := b.expr(n.args[1], argumentPath(n.path(), 1))
+		if err != nil {
+			return "", err
+		}
+		if b.dialect == SQLite {
+			return fmt.Sprintf("(instr(%s, %s) > 0)", text, value), nil
+		}
+		return fmt.Sprintf("(strpos(%s, %s) > 0)", text, value), nil
+	}
+
+	if len(array.items) == 0 {
+		return b.dialect.boolean(false), nil
+	}
+	items := make([]string, 0, len(array.items))
+	for i, item := range array.items {
+		expr, err := b.expr(item, argumentPath(array.path(), i))
+		if err != nil {
+			return "", err
+		}
+		items = append(items, expr)
+	}
+	return fmt.Sprintf("(%s IN (%s))", value, strings.Join(items, ", ")), nil
+}
+
+// arithmetic translates '+' and '*', which with a single argument convert it to a number.
+func (b *sqlBuilder) arithmetic(key string, args []string) string {
+	switch len(args) {
+	case 0:
+		if key == "*" {
+			return b.bind(1.0)
+		}
+		return b.bind(0.0)
+	case 1:
+		return fmt.Sprintf("CAST(%s AS %s)", args[0], b.dialect.float())
+	}
+	return join(args, " "+key+" ")
+}
+
+// extreme translates 'max' and 'min', which are GREATEST and LEAST in PostgreSQL.
+func (b *sqlBuilder) extreme(key string, args []string) string {
+	switch len(args) {
+	case 0:
+		return b.bind(0.0)
+	case 1:
+		return args[0]
+	}
+
+	function := strings.ToUpper(key)
+	if b.dialect == PostgreSQL {
+		function = map[string]string{"max": "GREATEST", "min": "LEAST"}[key]
+	}
+	return fmt.Sprintf("%s(%s)", function, strings.Join(args, ", "))
+}
+
+// join joins expressions with an operator within parentheses, a single expression needing none.
+func join(exprs []string, operator string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return "(" + strings.Join(exprs, operator) + ")"
+}
+
+// isNull reports whether a node is the literal null.
+func isNull(n node) bool {
+	literal, ok := n.(*literalNode)
+	return ok && literal.value == nil
+}
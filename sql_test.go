@@ -0,0 +1,93 @@
+package jsonlogic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var testColumns = Columns(map[string]string{
+	"temp":        "temp",
+	"pie.filling": "pie_filling",
+	"pie.price":   "pie_price",
+	"name":        "name",
+	"tags":        "tags",
+})
+
+func TestToSQL(t *testing.T) {
+	rules := map[string]struct {
+		sql  string
+		args []interface{}
+	}{
+		`{"and":[{"<":[{"var":"temp"}, 110]}, {"==":[{"var":"pie.filling"}, "apple"]}]}`: {`((temp < $1) AND (pie_filling = $2))`, []interface{}{110.0, "apple"}},
+		`{"or":[{"!=":[{"var":"name"}, null]}, {"!":{"var":"temp"}}]}`:                   {`((name IS NOT NULL) OR (NOT temp))`, []interface{}{}},
+		`{"<=":[1, {"var":"temp"}, 10]}`:                                                 {`($1 <= temp AND temp <= $2)`, []interface{}{1.0, 10.0}},
+		`{"in":[{"var":"pie.filling"}, ["apple", "cherry"]]}`:                            {`(pie_filling IN ($1, $2))`, []interface{}{"apple", "cherry"}},
+		`{"in":["pie", {"var":"name"}]}`:                                                 {`(strpos(name, $1) > 0)`, []interface{}{"pie"}},
+		`{"in":[{"var":"name"}, []]}`:                                                    {`FALSE`, []interface{}{}},
+		`{"missing":["name", "temp"]}`:                                                   {`((name IS NULL OR CAST(name AS TEXT) = '') OR (temp IS NULL OR CAST(temp AS TEXT) = ''))`, []interface{}{}},
+		`{"missing_some":[1, ["name"]]}`:                                                 {`(CASE WHEN (name IS NULL OR CAST(name AS TEXT) = '') THEN 0 ELSE 1 END < $1)`, []interface{}{1.0}},
+		`{">":[{"*":[{"var":"pie.price"}, {"/":[{"var":"temp"}, 2]}]}, {"-":[5]}]}`:      {`((pie_price * (CAST(temp AS DOUBLE PRECISION) / $1)) > (-CAST($2 AS NUMERIC)))`, []interface{}{2.0, 5.0}},
+		`{"==":[{"cat":[{"var":"name"}, "-", 1]}, "pie-1"]}`:                             {`((COALESCE(CAST(name AS TEXT), '') || COALESCE(CAST($1 AS TEXT), '') || COALESCE(CAST($2 AS TEXT), '')) = $3)`, []interface{}{"-", 1.0, "pie-1"}},
+		`{"==":[{"var":["temp", 0]}, {"max":[1, {"var":"pie.price"}]}]}`:                 {`(COALESCE(temp, $1) = GREATEST(CAST($2 AS NUMERIC), pie_price))`, []interface{}{0.0, 1.0}},
+		`{"if":[{"var":"tags"}, {"==":[{"var":"name"}, "a"]}, true]}`:                    {`(CASE WHEN tags THEN (name = $1) ELSE TRUE END)`, []interface{}{"a"}},
+		`{"==":[{"+":[1, 2]}, {"*":[{"var":"temp"}, 3]}]}`:                               {`((CAST($1 AS NUMERIC) + CAST($2 AS NUMERIC)) = (temp * CAST($3 AS NUMERIC)))`, []interface{}{1.0, 2.0, 3.0}},
+		`{"==":[{"%":[{"var":"temp"}, 2]}, {"-":[5, 1]}]}`:                               {`((CAST(temp AS NUMERIC) % CAST($1 AS NUMERIC)) = (CAST($2 AS NUMERIC) - CAST($3 AS NUMERIC)))`, []interface{}{2.0, 5.0, 1.0}},
+		`{"if":[]}`:                           {`NULL`, []interface{}{}},
+		`{"and":[{"var":"tags"}, {"?:":[]}]}`: {`(tags AND NULL)`, []interface{}{}},
+	}
+
+	for rule, expected := range rules {
+		sql, args, err := ToSQL(rule, PostgreSQL, testColumns)
+		if err != nil || sql != expected.sql || !reflect.DeepEqual(args, expected.args) {
+			t.Fatalf("rule %s should translate to %s %v, instead returned %s %v, %v", rule, expected.sql, expected.args, sql, args, err)
+		}
+	}
+}
+
+func TestToSQLite(t *testing.T) {
+	rules := map[string]string{
+		`{"and":[{"==":[{"var":"tags"}, true]}, {"in":["a", {"var":"name"}]}]}`: `((tags = 1) AND (instr(name, ?1) > 0))`,
+		`{"<":[{"/":[{"var":"temp"}, 3]}, {"min":[{"var":"temp"}, 3]}]}`:        `((CAST(temp AS REAL) / ?1) < MIN(temp, ?2))`,
+		`{"==":[{"%":[{"var":"temp"}, 2]}, {"+":[1, 2]}]}`:                      `((temp % ?1) = (?2 + ?3))`,
+	}
+
+	for rule, expected := range rules {
+		sql, _, err := ToSQL(rule, SQLite, testColumns)
+		if err != nil || sql != expected {
+			t.Fatalf("rule %s should translate to %s, instead returned %s, %v", rule, expected, sql, err)
+		}
+	}
+}
+
+func TestToSQLTranslationError(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("double", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return args[0].(float64) * 2, nil
+	})
+
+	rules := map[string]string{
+		`{"and":[true, {"some":[{"var":"tags"}, {"==":[{"var":""}, "a"]}]}]}`: "and[1].some",
+		`{"==":[{"var":"nope"}, 1]}`:                                          "==[0].var",
+		`{"==":[{"var":{"cat":["te", "mp"]}}, 1]}`:                            "==[0].var",
+		`{"==":[{"double":{"var":"temp"}}, 2]}`:                               "==[0].double",
+		`{"==":[{"var":"temp"}, [1]]}`:                                        "==[1]",
+		`{"substr":[{"var":"name"}, 1]}`:                                      "substr",
+	}
+
+	for rule, path := range rules {
+		_, _, err := engine.ToSQL(rule, PostgreSQL, testColumns)
+
+		var translation *TranslationError
+		if !errors.As(err, &translation) || translation.Path != path || translation.Target != "SQL" {
+			t.Fatalf("rule %s should fail to translate at %s, instead returned %v", rule, path, err)
+		}
+	}
+
+	if _, _, err := ToSQL(`{"var":"temp"}`, Dialect(0), testColumns); err == nil {
+		t.Fatal("unknown dialect should fail")
+	}
+	if _, _, err := ToSQL(`{"var":`, PostgreSQL, testColumns); err == nil {
+		t.Fatal("invalid rule should fail")
+	}
+}
@@ -0,0 +1,221 @@
+package jsonlogic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Trace is a record of how an operator within a rule was evaluated, along with the traces of the operators
+// within its arguments, so the reason a rule gave its result can be followed.
+type Trace struct {
+	// Operator is the key of the operator, such as '<'.
+	Operator string `json:"operator"`
+	// Path is the path of the operator within the rule, such as 'and[1].<'.
+	Path string `json:"path"`
+	// Args are the values the arguments evaluated to, nil for those which weren't evaluated.
+	// An argument evaluated many times, such as the second argument of 'map', holds the last value.
+	Args []interface{} `json:"args"`
+	// Result is what the operator returned.
+	Result interface{} `json:"result"`
+	// Err is the error the operator failed with, if any.
+	Err error `json:"-"`
+	// ShortCircuited is true when some arguments were never evaluated, as with 'and' stopping at a falsy value.
+	ShortCircuited bool `json:"shortCircuited"`
+	// Children are the traces of the operators within the arguments, in the order they were evaluated.
+	Children []*Trace `json:"children,omitempty"`
+
+	evaluated []bool
+}
+
+// ApplyWithTrace is Apply which also returns a trace of how the rule was evaluated.
+// The trace is returned even when the evaluation fails, up to the operator which failed.
+func ApplyWithTrace(rule string, data string) (interface{}, *Trace, error) {
+	return DefaultEngine.ApplyWithTrace(rule, data)
+}
+
+// ApplyWithTrace parses the rule and evaluates it against optional data using the operators of the engine,
+// also returning a trace of how the rule was evaluated.
+func (e *Engine) ApplyWithTrace(rule string, data string) (interface{}, *Trace, error) {
+	program, err := e.Compile(rule)
+	if err != nil {
+		return false, nil, err
+	}
+
+	result, trace, err := program.EvaluateWithTrace(data)
+	if err != nil {
+		return false, trace, err
+	}
+
+	return result, trace, nil
+}
+
+// EvaluateWithTrace runs the compiled rule against optional json data, also returning a trace of how it was evaluated.
+// When the rule itself is an operator the trace is of that operator, otherwise, such as for an array of operators,
+// it is a trace without an operator whose children are the traces of the operators within the rule.
+func (p *Program) EvaluateWithTrace(data string) (interface{}, *Trace, error) {
+	root := &Trace{}
+	ev := newEvaluation(context.Background(), p.engine, nil)
+	ev.trace = root
+
+	result, err := p.evaluate(ev, data)
+	if len(root.Children) == 1 && root.Children[0].Path == root.Children[0].Operator {
+		return result, root.Children[0], err
+	}
+
+	root.Result, root.Err = result, err
+	return result, root, err
+}
+
+// evalTraced evaluates the operator, recording its arguments and result in a trace added to the trace of
+// the operator it is within.
+func (n *operatorNode) evalTraced(ev *evaluation, data interface{}) (interface{}, error) {
+	trace := &Trace{Operator: n.key, Path: n.path(), Args: make([]interface{}, len(n.args)), evaluated: make([]bool, len(n.args))}
+	parent := ev.trace
+	parent.Children = append(parent.Children, trace)
+
+	// The arguments are wrapped so their values are recorded however the operator evaluates them
+	traced := &operatorNode{key: n.key, rule: n.rule, at: n.at, args: make([]node, len(n.args))}
+	for i, arg := range n.args {
+		traced.args[i] = &tracedArgument{node: arg, trace: trace, index: i}
+	}
+
+	ev.trace = trace
+	result, err := traced.apply(ev, data)
+	result, err = n.finish(ev, result, err)
+	ev.trace = parent
+
+	trace.Result, trace.Err = result, err
+	if err == nil && !n.legacy(ev) {
+		for _, evaluated := range trace.evaluated {
+			trace.ShortCircuited = trace.ShortCircuited || !evaluated
+		}
+	}
+	return result, err
+}
+
+// legacy reports whether the operator is a custom operator registered with AddOperator, which parses its own
+// arguments so they are never evaluated as far as a trace can see.
+func (n *operatorNode) legacy(ev *evaluation) bool {
+	if _, ok := ev.engine.operation(n.key); ok {
+		return false
+	}
+	_, ok := ev.engine.operator(n.key)
+	return ok
+}
+
+// tracedArgument is an argument of a traced operator which records its value in the trace.
+type tracedArgument struct {
+	node  node
+	trace *Trace
+	index int
+}
+
+func (a *tracedArgument) eval(ev *evaluation, data interface{}) (interface{}, error) {
+	value, err := a.node.eval(ev, data)
+	a.trace.Args[a.index] = value
+	a.trace.evaluated[a.index] = true
+	return value, err
+}
+
+// infixOperators are rendered between their arguments, such as 'temp (120) < 110'.
+var infixOperators = map[string]bool{
+	"==": true, "===": true, "!=": true, "!==": true, ">": true, ">=": true, "<": true, "<=": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true, "in": true,
+}
+
+// String renders the trace as an indented tree with a line for each operator, such as:
+//
+//	and(<(...) (false), _) was false (short-circuited)
+//	  temp (120) < 110 was false
+//
+// A 'var' is shown within the line of the operator it is an argument of rather than on its own line.
+func (t *Trace) String() string {
+	b := &strings.Builder{}
+	t.render(b, 0)
+	return b.String()
+}
+
+func (t *Trace) render(b *strings.Builder, depth int) {
+	if t.Operator != "" {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString(t.describe())
+		b.WriteString("\n")
+		depth++
+	}
+
+	for _, child := range t.Children {
+		if t.Operator != "" && child.Operator == "var" && len(child.Children) == 0 && child.Err == nil {
+			continue
+		}
+		child.render(b, depth)
+	}
+}
+
+// describe is the line of the trace for this operator alone.
+func (t *Trace) describe() string {
+	args := make([]string, 0, len(t.Args))
+	for i := range t.Args {
+		args = append(args, t.describeArg(i))
+	}
+
+	var line string
+	switch {
+	case t.Operator == "var" && len(t.Args) > 0:
+		line = "var " + varName(t.Args[0])
+	case infixOperators[t.Operator] && len(args) > 1:
+		line = strings.Join(args, " "+t.Operator+" ")
+	default:
+		line = fmt.Sprintf("%s(%s)", t.Operator, strings.Join(args, ", "))
+	}
+
+	if t.Err != nil {
+		return line + " failed: " + t.Err.Error()
+	}
+	line += " was " + traceValue(t.Result)
+	if t.ShortCircuited {
+		line += " (short-circuited)"
+	}
+	return line
+}
+
+// describeArg describes an argument by its value, along with the key for a 'var' or the operator which produced it.
+func (t *Trace) describeArg(i int) string {
+	if i < len(t.evaluated) && !t.evaluated[i] {
+		return "_"
+	}
+
+	var child *Trace
+	prefix := fmt.Sprintf("%s[%d].", t.Path, i)
+	for _, c := range t.Children {
+		if c.Path == prefix+c.Operator {
+			child = c
+		}
+	}
+
+	switch {
+	case child == nil:
+		return traceValue(t.Args[i])
+	case child.Operator == "var" && len(child.Args) > 0:
+		return fmt.Sprintf("%s (%s)", varName(child.Args[0]), traceValue(t.Args[i]))
+	}
+	return fmt.Sprintf("%s(...) (%s)", child.Operator, traceValue(t.Args[i]))
+}
+
+// varName is how the key of a 'var' is shown, as json unless it is a plain name.
+func varName(key interface{}) string {
+	if name, ok := key.(string); ok && name != "" {
+		return name
+	}
+	return traceValue(key)
+}
+
+// traceValue formats a value as json.
+func traceValue(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,95 @@
+package jsonlogic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyWithTrace(t *testing.T) {
+	rule := `{"and":[{"<":[{"var":"temp"}, 110]}, {"==":[{"var":"pie.filling"}, "apple"]}]}`
+
+	result, trace, err := ApplyWithTrace(rule, `{"temp":120, "pie":{"filling":"apple"}}`)
+	if err != nil || result != false {
+		t.Fatalf("rule should return false, instead returned %v, %v", result, err)
+	}
+
+	if trace.Operator != "and" || trace.Path != "and" || trace.Result != false || !trace.ShortCircuited {
+		t.Fatalf("trace should be of a short-circuited and, instead was %#v", trace)
+	}
+	if !reflect.DeepEqual(trace.Args, []interface{}{false, nil}) || len(trace.Children) != 1 {
+		t.Fatalf("trace should only have the first argument, instead was %#v", trace)
+	}
+
+	less := trace.Children[0]
+	if less.Operator != "<" || less.Path != "and[0].<" || less.ShortCircuited {
+		t.Fatalf("trace should have the <, instead was %#v", less)
+	}
+	if !reflect.DeepEqual(less.Args, []interface{}{120.0, 110.0}) || less.Result != false {
+		t.Fatalf("trace should have the arguments and result of <, instead was %#v", less)
+	}
+
+	variable := less.Children[0]
+	if variable.Operator != "var" || variable.Path != "and[0].<[0].var" || variable.Result != 120.0 {
+		t.Fatalf("trace should have the var, instead was %#v", variable)
+	}
+}
+
+func TestTraceString(t *testing.T) {
+	rule := `{"if":[{"<":[{"var":"temp"}, 0]}, "freezing", {"<":[{"var":"temp"}, 100]}, "liquid", "gas"]}`
+
+	_, trace, err := ApplyWithTrace(rule, `{"temp":120}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `if(<(...) (false), _, <(...) (false), _, "gas") was "gas" (short-circuited)
+  temp (120) < 0 was false
+  temp (120) < 100 was false
+`
+	if trace.String() != expected {
+		t.Fatalf("trace should render as\n%s\ninstead rendered\n%s", expected, trace)
+	}
+}
+
+func TestTraceScoped(t *testing.T) {
+	_, trace, err := ApplyWithTrace(`{"map":[{"var":"items"}, {"*":[{"var":""}, 2]}]}`, `{"items":[1, 2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `map(items ([1,2]), *(...) (4)) was [2,4]
+  "" (1) * 2 was 2
+  "" (2) * 2 was 4
+`
+	if trace.String() != expected {
+		t.Fatalf("trace should render as\n%s\ninstead rendered\n%s", expected, trace)
+	}
+}
+
+func TestTraceError(t *testing.T) {
+	result, trace, err := ApplyWithTrace(`[{"var":"a"}, {"+":[1, {"nope":[]}]}]`, `{"a":1}`)
+	if err == nil || result != false {
+		t.Fatalf("rule should fail, instead returned %v", result)
+	}
+
+	if trace.Operator != "" || len(trace.Children) != 2 {
+		t.Fatalf("trace should have a root for each operator, instead was %#v", trace)
+	}
+
+	plus := trace.Children[1]
+	if plus.Err == nil || plus.ShortCircuited || plus.Children[0].Err == nil {
+		t.Fatalf("trace should have the error of the operator, instead was %#v", plus)
+	}
+}
+
+func TestTraceLegacyOperator(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperator("legacy", func(rule string, data string) interface{} {
+		return true
+	})
+
+	_, trace, err := engine.ApplyWithTrace(`{"legacy":[1, 2]}`, `{}`)
+	if err != nil || trace.ShortCircuited {
+		t.Fatalf("trace of a legacy operator shouldn't be short-circuited, instead was %#v, %v", trace, err)
+	}
+}
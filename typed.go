@@ -0,0 +1,104 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ApplyBool evaluates rule against data and returns whether the result is truthy, where 0, "", null and
+// empty arrays are false.
+func ApplyBool(rule string, data string) (bool, error) {
+	return DefaultEngine.ApplyBool(rule, data)
+}
+
+// ApplyFloat evaluates rule against data and converts the result to a number the way JavaScript does,
+// so "1.5" is 1.5 and null is 0. A result which isn't a number, such as "abc", returns a *ConversionError.
+func ApplyFloat(rule string, data string) (float64, error) {
+	return DefaultEngine.ApplyFloat(rule, data)
+}
+
+// ApplyString evaluates rule against data and converts the result to a string the way JavaScript does,
+// so 1 is "1" and true is "true". Null, arrays and objects return a *ConversionError.
+func ApplyString(rule string, data string) (string, error) {
+	return DefaultEngine.ApplyString(rule, data)
+}
+
+// ApplyAs evaluates rule against data and decodes the result into T, such as a struct or slice, following
+// the same mapping as encoding/json. A result which doesn't fit T returns a *ConversionError.
+func ApplyAs[T any](rule string, data string) (T, error) {
+	result, err := Apply(rule, data)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return As[T](result)
+}
+
+// ApplyBool evaluates rule against data using the operators of the engine, see ApplyBool.
+func (e *Engine) ApplyBool(rule string, data string) (bool, error) {
+	result, err := e.Apply(rule, data)
+	if err != nil {
+		return false, err
+	}
+	return AsBool(result), nil
+}
+
+// ApplyFloat evaluates rule against data using the operators of the engine, see ApplyFloat.
+func (e *Engine) ApplyFloat(rule string, data string) (float64, error) {
+	result, err := e.Apply(rule, data)
+	if err != nil {
+		return 0, err
+	}
+	return AsFloat(result)
+}
+
+// ApplyString evaluates rule against data using the operators of the engine, see ApplyString.
+func (e *Engine) ApplyString(rule string, data string) (string, error) {
+	result, err := e.Apply(rule, data)
+	if err != nil {
+		return "", err
+	}
+	return AsString(result)
+}
+
+// AsBool converts the result of a rule to a bool by JsonLogic truthiness.
+func AsBool(value interface{}) bool {
+	return truthy(value)
+}
+
+// AsFloat converts the result of a rule to a number the way JavaScript does.
+func AsFloat(value interface{}) (float64, error) {
+	number := toNumber(value)
+	if math.IsNaN(number) {
+		return 0, &ConversionError{Value: value, Type: "float64"}
+	}
+	return number, nil
+}
+
+// AsString converts the result of a rule to a string the way JavaScript does.
+func AsString(value interface{}) (string, error) {
+	if _, valueType := primitive(value); valueType == jsNull || valueType == jsObject {
+		return "", &ConversionError{Value: value, Type: "string"}
+	}
+	return toString(value), nil
+}
+
+// As converts the result of a rule into T, either directly when it already is a T or by encoding it as json
+// and decoding that into T.
+func As[T any](value interface{}) (T, error) {
+	if result, ok := value.(T); ok {
+		return result, nil
+	}
+
+	var result T
+	raw, err := json.Marshal(value)
+	if err == nil {
+		err = json.Unmarshal(raw, &result)
+	}
+	if err != nil {
+		var zero T
+		return zero, &ConversionError{Value: value, Type: fmt.Sprintf("%T", result), Err: err}
+	}
+	return result, nil
+}
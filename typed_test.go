@@ -0,0 +1,124 @@
+package jsonlogic
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestApplyBool(t *testing.T) {
+	rules := map[string]bool{
+		`{"var":"name"}`:              true,
+		`{"var":"tags"}`:              false,
+		`{"var":"missing"}`:           false,
+		`{"<":[{"var":"age"}, 18]}`:   false,
+		`{"merge":[{"var":"tags"}]}`:  false,
+		`{"cat":[{"var":"name"}, 1]}`: true,
+	}
+
+	for rule, expected := range rules {
+		result, err := ApplyBool(rule, `{"name":"Ann", "age":30, "tags":[]}`)
+		if err != nil || result != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, expected, result, err)
+		}
+	}
+}
+
+func TestApplyBoolError(t *testing.T) {
+	result, err := ApplyBool(`{"nope":[]}`, `{}`)
+
+	if result != false || err == nil {
+		t.Fatalf("rule should return false with an error, instead returned %v, %v", result, err)
+	}
+}
+
+func TestApplyFloat(t *testing.T) {
+	rules := map[string]float64{
+		`{"+":[1, 2]}`:    3,
+		`{"var":"price"}`: 1.5,
+		`{"var":"count"}`: 4,
+		`{"var":"none"}`:  0,
+		`true`:            1,
+	}
+
+	for rule, expected := range rules {
+		result, err := ApplyFloat(rule, `{"price":1.5, "count":"4"}`)
+		if err != nil || result != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, expected, result, err)
+		}
+	}
+}
+
+func TestApplyFloatConversionError(t *testing.T) {
+	_, err := ApplyFloat(`{"var":"name"}`, `{"name":"Ann"}`)
+
+	var conversion *ConversionError
+	if !errors.As(err, &conversion) || conversion.Type != "float64" || conversion.Value != "Ann" {
+		t.Fatalf("rule should return a conversion error, instead returned %v", err)
+	}
+
+	// A number which is already NaN isn't a number either
+	if _, err := ApplyFloat(`{"/":[0, 0]}`, ``); !errors.As(err, &conversion) {
+		t.Fatalf("NaN should return a conversion error, instead returned %v", err)
+	}
+	if _, err := AsFloat(math.NaN()); !errors.As(err, &conversion) {
+		t.Fatalf("NaN should return a conversion error, instead returned %v", err)
+	}
+}
+
+func TestApplyString(t *testing.T) {
+	rules := map[string]string{
+		`{"cat":["a", "b"]}`: "ab",
+		`{"+":[1, 2]}`:       "3",
+		`{"/":[1, 4]}`:       "0.25",
+		`{"==":[1, 1]}`:      "true",
+	}
+
+	for rule, expected := range rules {
+		result, err := ApplyString(rule, `{}`)
+		if err != nil || result != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v, %v", rule, expected, result, err)
+		}
+	}
+}
+
+func TestApplyStringConversionError(t *testing.T) {
+	for _, rule := range []string{`{"var":"missing"}`, `{"merge":[1, 2]}`} {
+		_, err := ApplyString(rule, `{}`)
+
+		var conversion *ConversionError
+		if !errors.As(err, &conversion) {
+			t.Fatalf("rule %s should return a conversion error, instead returned %v", rule, err)
+		}
+	}
+}
+
+func TestApplyAs(t *testing.T) {
+	type pie struct {
+		Filling string `json:"filling"`
+		Slices  int    `json:"slices"`
+	}
+
+	pies, err := ApplyAs[[]pie](`{"filter":[{"var":"pies"}, {">":[{"var":"slices"}, 2]}]}`, `{"pies":[{"filling":"apple","slices":8},{"filling":"cherry","slices":1}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pies, []pie{{Filling: "apple", Slices: 8}}) {
+		t.Fatalf("rule should return the apple pie, instead returned %#v", pies)
+	}
+
+	count, err := ApplyAs[int](`{"+":[1, 2]}`, `{}`)
+	if err != nil || count != 3 {
+		t.Fatalf("rule should return 3, instead returned %v, %v", count, err)
+	}
+}
+
+func TestApplyAsConversionError(t *testing.T) {
+	_, err := ApplyAs[int](`{"/":[1, 2]}`, `{}`)
+
+	var conversion *ConversionError
+	if !errors.As(err, &conversion) || conversion.Type != "int" {
+		t.Fatalf("rule should return a conversion error, instead returned %v", err)
+	}
+}
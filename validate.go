@@ -0,0 +1,256 @@
+package jsonlogic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/buger/jsonparser"
+)
+
+// Severity is how serious a problem found by Validate is.
+type Severity string
+
+const (
+	// SeverityError is a problem which makes the rule fail or behave other than intended, such as an unknown operator.
+	SeverityError Severity = "error"
+	// SeverityWarning is a part of the rule which does nothing, such as a branch of 'if' which can never be reached.
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a rule by Validate. Path is the path of the offending node, see errors.go.
+// Err is the error the problem would be returned as by Apply, such as an *ArityError, or nil when there isn't one.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+	Err      error    `json:"-"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Path == "" {
+		return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+	}
+	return fmt.Sprintf("%s at %s: %s", d.Severity, d.Path, d.Message)
+}
+
+// Validate checks a rule without evaluating it using the operators of the default engine, see Engine.Validate.
+func Validate(rule string) []Diagnostic {
+	return DefaultEngine.Validate(rule)
+}
+
+// Validate checks a rule without evaluating it, so bad rules can be rejected before they are stored.
+// It reports json which isn't valid, objects with an operator alongside other keys, operators which are neither
+// built in nor registered with the engine, the wrong number of arguments, literal arguments of the wrong type,
+// such as 'substr' of a number, and branches of 'if' which can never be reached. A valid rule returns no diagnostics.
+func (e *Engine) Validate(rule string) []Diagnostic {
+	program, err := e.Compile(rule)
+	if err != nil {
+		diagnostic := Diagnostic{Severity: SeverityError, Message: err.Error(), Err: err}
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			diagnostic.Path, diagnostic.Message = parseErr.Path, parseErr.Err.Error()
+		}
+		return []Diagnostic{diagnostic}
+	}
+
+	v := &validator{engine: e}
+	v.node(program.root, "")
+	return v.diagnostics
+}
+
+// validator walks a compiled rule collecting diagnostics.
+type validator struct {
+	engine      *Engine
+	diagnostics []Diagnostic
+}
+
+func (v *validator) report(severity Severity, path string, err error, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...), Err: err})
+}
+
+func (v *validator) node(n node, path string) {
+	switch n := n.(type) {
+	case *operatorNode:
+		v.operator(n)
+	case *arrayNode:
+		for i, item := range n.items {
+			v.node(item, argumentPath(path, i))
+		}
+	case *objectNode:
+		v.object(n, path)
+	}
+}
+
+func (v *validator) operator(n *operatorNode) {
+	// Custom operators take precedence over built in ones, those registered with AddOperator parse their own arguments
+	if _, ok := v.engine.operator(n.key); ok {
+		return
+	}
+	if _, ok := v.engine.operation(n.key); !ok {
+		v.builtIn(n)
+	}
+
+	for i, arg := range n.args {
+		v.node(arg, argumentPath(n.path(), i))
+	}
+}
+
+// builtIn checks the arguments of a built in operator.
+func (v *validator) builtIn(n *operatorNode) {
+	arity, ok := operatorArity[n.key]
+	if !ok {
+		v.report(SeverityError, n.path(), &UnknownOperatorError{Operator: n.key, Path: n.path()}, "unknown operator %q", n.key)
+		return
+	}
+	if len(n.args) < arity.min || (arity.max >= 0 && len(n.args) > arity.max) {
+		err := &ArityError{Operator: n.key, Path: n.path(), Min: arity.min, Max: arity.max, Got: len(n.args)}
+		v.report(SeverityError, n.path(), err, "operator %q expects %s arguments, got %d", n.key, err.expects(), len(n.args))
+	}
+
+	expects := literalTypes[n.key]
+	for i, arg := range n.args {
+		if len(expects) == 0 || (i >= len(expects) && arity.max >= 0) {
+			break
+		}
+		expected := expects[len(expects)-1]
+		if i < len(expects) {
+			expected = expects[i]
+		}
+
+		value, ok := literal(arg)
+		if ok && expected != "" && !literalMatches(expected, value) {
+			path := argumentPath(n.path(), i)
+			err := &TypeError{Operator: n.key, Path: path, Expected: expected, Value: value}
+			v.report(SeverityError, path, err, "operator %q expects %s, got %s", n.key, expected, traceValue(value))
+		}
+	}
+
+	if n.key == "if" || n.key == "?:" {
+		v.branches(n)
+	}
+}
+
+// branches reports the branches of 'if' which are never reached as a condition before them is a literal.
+func (v *validator) branches(n *operatorNode) {
+	for i := 0; i+1 < len(n.args); i += 2 {
+		condition, ok := literal(n.args[i])
+		if !ok {
+			continue
+		}
+
+		path := argumentPath(n.path(), i)
+		if !truthy(condition) {
+			v.report(SeverityWarning, argumentPath(n.path(), i+1), nil, "branch is unreachable as the condition at %s is always false", path)
+			continue
+		}
+		for j := i + 2; j < len(n.args); j++ {
+			v.report(SeverityWarning, argumentPath(n.path(), j), nil, "branch is unreachable as the condition at %s is always true", path)
+		}
+		return
+	}
+}
+
+// object reports an object which has an operator among several keys, so is treated as data rather than evaluated.
+func (v *validator) object(n *objectNode, path string) {
+	keys := 0
+	operator := ""
+	jsonparser.ObjectEach(n.raw, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		keys++
+		if operator == "" && v.known(string(key)) {
+			operator = string(key)
+		}
+		return nil
+	})
+
+	if keys > 1 && operator != "" {
+		v.report(SeverityError, path, nil, "object has operator %q among %d keys, an operator must be the only key of its object", operator, keys)
+	}
+}
+
+// known reports whether the key is a built in operator or one registered with the engine.
+func (v *validator) known(key string) bool {
+	_, builtIn := operatorArity[key]
+	_, operation := v.engine.operation(key)
+	_, operator := v.engine.operator(key)
+	return builtIn || operation || operator
+}
+
+// Types of literal arguments expected by built in operators.
+const (
+	expectNumber        = "a number"
+	expectString        = "a string"
+	expectArray         = "an array"
+	expectKey           = "a string or number"
+	expectStringOrArray = "a string or array"
+)
+
+// literalTypes holds the types the arguments of built in operators are expected to be when they are literals.
+// The last type applies to any further arguments of operators which take any number, an empty type is anything.
+var literalTypes = map[string][]string{
+	"var":          {expectKey},
+	"missing_some": {expectNumber, expectArray},
+	"max":          {expectNumber},
+	"min":          {expectNumber},
+	"+":            {expectNumber},
+	"-":            {expectNumber},
+	"*":            {expectNumber},
+	"/":            {expectNumber, expectNumber},
+	"%":            {expectNumber, expectNumber},
+	"percentage":   {expectNumber, expectNumber},
+	"in":           {"", expectStringOrArray},
+	"substr":       {expectString, expectNumber, expectNumber},
+	"all":          {expectArray},
+	"some":         {expectArray},
+	"none":         {expectArray},
+	"map":          {expectArray},
+	"reduce":       {expectArray},
+	"filter":       {expectArray},
+}
+
+// literalMatches reports whether a literal value is of the expected type, where strings of numbers are numbers.
+func literalMatches(expected string, value interface{}) bool {
+	switch value := value.(type) {
+	case float64:
+		return expected == expectNumber || expected == expectKey
+	case string:
+		if expected == expectNumber {
+			return !math.IsNaN(stringToNumber(value))
+		}
+		return expected == expectString || expected == expectKey || expected == expectStringOrArray
+	case []interface{}:
+		return expected == expectArray || expected == expectStringOrArray
+	case nil:
+		return expected == expectKey
+	}
+	return false
+}
+
+// literal returns the value of a node which doesn't depend on the data, being a literal, an object of data or
+// an array of them.
+func literal(n node) (interface{}, bool) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, true
+	case *objectNode:
+		var value map[string]interface{}
+		return value, json.Unmarshal(n.raw, &value) == nil
+	case *arrayNode:
+		items := make([]interface{}, 0, len(n.items))
+		for _, item := range n.items {
+			value, ok := literal(item)
+			if !ok {
+				return nil, false
+			}
+			items = append(items, value)
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// argumentPath is the path of an argument of the operator or element of the array found at path.
+func argumentPath(path string, index int) string {
+	return fmt.Sprintf("%s[%d]", path, index)
+}
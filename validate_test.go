@@ -0,0 +1,94 @@
+package jsonlogic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	rules := []string{
+		`{"and":[{"<":[{"var":"temp"}, 110]}, {"==":[{"var":"pie.filling"}, "apple"]}]}`,
+		`{"if":[{"var":"a"}, "yes", "no"]}`,
+		`{"map":[{"var":"items"}, {"*":[{"var":""}, "2"]}]}`,
+		`{"in":["a", ["a", "b"]]}`,
+		`{"substr":[{"var":"name"}, 1]}`,
+		`{"cat":[{"a":1, "b":2}, 1]}`,
+		`[1, "a", null]`,
+	}
+
+	for _, rule := range rules {
+		if diagnostics := Validate(rule); len(diagnostics) != 0 {
+			t.Fatalf("rule %s should be valid, instead returned %v", rule, diagnostics)
+		}
+	}
+}
+
+func TestValidateDiagnostics(t *testing.T) {
+	rules := map[string]Diagnostic{
+		`{"and":[true, {"nope":[]}]}`:                   {Severity: SeverityError, Path: "and[1].nope", Message: `unknown operator "nope"`},
+		`{"substr":["abc"]}`:                            {Severity: SeverityError, Path: "substr", Message: `operator "substr" expects 2 to 3 arguments, got 1`},
+		`{"substr":[123, 1]}`:                           {Severity: SeverityError, Path: "substr[0]", Message: `operator "substr" expects a string, got 123`},
+		`{"+":[1, {"var":"a"}, "b"]}`:                   {Severity: SeverityError, Path: "+[2]", Message: `operator "+" expects a number, got "b"`},
+		`{"map":["abc", {"var":""}]}`:                   {Severity: SeverityError, Path: "map[0]", Message: `operator "map" expects an array, got "abc"`},
+		`{"or":[{"==":[1, 1], "!=":[1, 2]}]}`:           {Severity: SeverityError, Path: "or[0]", Message: `object has operator "==" among 2 keys, an operator must be the only key of its object`},
+		`{"if":[false, "a", "b"]}`:                      {Severity: SeverityWarning, Path: "if[1]", Message: "branch is unreachable as the condition at if[0] is always false"},
+		`{"and":[{"var":"a"}, {"if":[[1], "a", "b"]}]}`: {Severity: SeverityWarning, Path: "and[1].if[2]", Message: "branch is unreachable as the condition at and[1].if[0] is always true"},
+		`{"and":[}`:     {Severity: SeverityError, Path: "and", Message: "invalid character '}' looking for beginning of value"},
+		`{"var":"a"} x`: {Severity: SeverityError, Path: "", Message: "invalid character 'x' after the rule at offset 12"},
+		`{"var":"a"}}`:  {Severity: SeverityError, Path: "", Message: "invalid character '}' after the rule at offset 11"},
+	}
+
+	for rule, expected := range rules {
+		diagnostics := Validate(rule)
+		if len(diagnostics) != 1 {
+			t.Fatalf("rule %s should have one diagnostic, instead returned %v", rule, diagnostics)
+		}
+
+		diagnostic := diagnostics[0]
+		diagnostic.Err = nil
+		if diagnostic != expected {
+			t.Fatalf("rule %s should return %v, instead returned %v", rule, expected, diagnostic)
+		}
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	diagnostics := Validate(`{"if":[true, {"nope":[]}, {"substr":[1]}]}`)
+	if len(diagnostics) != 4 {
+		t.Fatalf("rule should have four diagnostics, instead returned %v", diagnostics)
+	}
+
+	var unknown *UnknownOperatorError
+	var arity *ArityError
+	var typeErr *TypeError
+	if !errors.As(diagnostics[1].Err, &unknown) || !errors.As(diagnostics[2].Err, &arity) || !errors.As(diagnostics[3].Err, &typeErr) {
+		t.Fatalf("diagnostics should have the errors Apply would return, instead returned %#v", diagnostics)
+	}
+	if diagnostics[0].Severity != SeverityWarning || diagnostics[0].Path != "if[2]" || diagnostics[0].Err != nil {
+		t.Fatalf("diagnostic should be of the unreachable branch, instead returned %v", diagnostics[0])
+	}
+}
+
+func TestValidateCustomOperators(t *testing.T) {
+	engine := NewEngine()
+	engine.AddOperation("double", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return args[0].(float64) * 2, nil
+	})
+	engine.AddOperation("substr", func(ctx *Context, args []interface{}) (interface{}, error) {
+		return args[0], nil
+	})
+	engine.AddOperator("legacy", func(rule string, data string) interface{} {
+		return true
+	})
+
+	rules := []string{`{"double":[{"var":"a"}]}`, `{"substr":[123]}`, `{"legacy":{"nope":[]}}`}
+	for _, rule := range rules {
+		if diagnostics := engine.Validate(rule); len(diagnostics) != 0 {
+			t.Fatalf("rule %s should be valid, instead returned %v", rule, diagnostics)
+		}
+	}
+
+	if diagnostics := engine.Validate(`{"double":[{"nope":[]}]}`); len(diagnostics) != 1 || diagnostics[0].Path != "double[0].nope" {
+		t.Fatalf("arguments of custom operators should be validated, instead returned %v", diagnostics)
+	}
+}